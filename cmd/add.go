@@ -14,27 +14,63 @@ var addCmd = &cobra.Command{
 	Short: "Add license headers to files",
 	Long:  `Add license headers to files that don't already have them`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		if cfgLicense == "" {
-			return fmt.Errorf("license file (--license) is required for add command")
+		if cfgLicense == "" && cfgLicenseID == "" && len(cfgRules) == 0 {
+			return fmt.Errorf("license file (--license) or --license-id is required for add command")
+		}
+
+		logOutput, err := reportLogOutput(cmd)
+		if err != nil {
+			return err
 		}
 
 		appCfg := config.AppConfig{
 			// File paths
-			LicenseFile: cfgLicense,
-			Inputs:      ProcessPatterns(cfgInputs),
-			Skips:       ProcessPatterns(cfgSkips),
+			LicenseFile:       cfgLicense,
+			LicenseID:         cfgLicenseID,
+			HeaderMode:        cfgHeaderMode,
+			Inputs:            ProcessPatterns(cfgInputs),
+			Skips:             ProcessPatterns(cfgSkips),
+			Since:             cfgSince,
+			Staged:            cfgStaged,
+			TrackedOnly:       cfgTrackedOnly,
+			NoGitignore:       cfgNoGitignore,
+			NoGitattributes:   cfgNoGitattributes,
+			Encodings:         cfgEncodings,
+			GeneratedPolicy:   cfgGenerated,
+			GeneratedPatterns: cfgGeneratedPatterns,
+			MaxSize:           cfgMaxSize,
+			MaxLines:          cfgMaxLines,
 
 			// Style settings
 			HeaderStyle:       cfgPresetStyle,
 			CommentStyle:      "go", // default
 			ForceCommentStyle: cfgForceCommentStyle,
 
+			// License template values
+			Holder:     cfgHolder,
+			Year:       cfgYear,
+			YearPolicy: cfgYearPolicy,
+			Vars:       cfgVars,
+
 			// Behavior flags
-			LogLevel: logger.ParseLogLevel(cfgLogLevel),
+			LogLevel:      logger.ParseLogLevel(cfgLogLevel),
+			LogOutput:     logOutput,
+			Jobs:          cfgJobs,
+			DryRun:        cfgDryRun,
+			ShowDiff:      cfgDiff,
+			PatchFile:     cfgPatch,
+			PatchFormat:   cfgPatchFormat,
+			OutputDir:     cfgOutputDir,
+			CopyUnchanged: cfgCopyUnchanged,
+			StateDir:      stateDir(),
+			NoJournal:     cfgNoJournal,
 
 			Force:       false,
 			IgnoreFail:  false,
 			IsPreCommit: false,
+
+			Rules:   cfgRules,
+			RuleDir: cfgRuleDir,
 		}
 
 		procCfg, err := appCfg.ToProcessorConfig()
@@ -46,6 +82,9 @@ var addCmd = &cobra.Command{
 		err = p.Add()
 
 		cmd.SilenceUsage = true
+		if reportErr := writeReport(cmd, p); reportErr != nil {
+			return reportErr
+		}
 		return err
 	},
 }
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"github.com/jeeftor/license-manager/internal/config"
+	"github.com/jeeftor/license-manager/internal/logger"
+	"github.com/jeeftor/license-manager/internal/processor"
+	"github.com/spf13/cobra"
+)
+
+var bumpYearCmd = &cobra.Command{
+	Use:   "bump-year",
+	Short: "Extend copyright years in license headers",
+	Long: `Extend the copyright years of existing license headers to the current year,
+or the one given with --year, e.g. "2024" becomes "2024-2026".
+
+Only the years are rewritten, the header style, the license wording and the
+rest of the file are left unchanged. No license file is needed.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		logOutput, err := reportLogOutput(cmd)
+		if err != nil {
+			return err
+		}
+
+		appCfg := config.AppConfig{
+			// File paths
+			Inputs:            ProcessPatterns(cfgInputs),
+			Skips:             ProcessPatterns(cfgSkips),
+			Since:             cfgSince,
+			Staged:            cfgStaged,
+			TrackedOnly:       cfgTrackedOnly,
+			NoGitignore:       cfgNoGitignore,
+			NoGitattributes:   cfgNoGitattributes,
+			Encodings:         cfgEncodings,
+			GeneratedPolicy:   cfgGenerated,
+			GeneratedPatterns: cfgGeneratedPatterns,
+			MaxSize:           cfgMaxSize,
+			MaxLines:          cfgMaxLines,
+
+			// Style settings
+			HeaderStyle:       cfgPresetStyle,
+			CommentStyle:      "go", // default
+			ForceCommentStyle: cfgForceCommentStyle,
+
+			// License template values
+			Year: cfgYear,
+
+			// Behavior flags
+			LogLevel:      logger.ParseLogLevel(cfgLogLevel),
+			LogOutput:     logOutput,
+			Jobs:          cfgJobs,
+			DryRun:        cfgDryRun,
+			ShowDiff:      cfgDiff,
+			PatchFile:     cfgPatch,
+			PatchFormat:   cfgPatchFormat,
+			OutputDir:     cfgOutputDir,
+			CopyUnchanged: cfgCopyUnchanged,
+			StateDir:      stateDir(),
+			NoJournal:     cfgNoJournal,
+		}
+
+		procCfg, err := appCfg.ToProcessorConfig()
+		if err != nil {
+			return err
+		}
+
+		p := processor.NewFileProcessor(procCfg)
+		err = p.BumpYear()
+
+		cmd.SilenceUsage = true
+		if reportErr := writeReport(cmd, p); reportErr != nil {
+			return reportErr
+		}
+		return err
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(bumpYearCmd)
+}
@@ -19,11 +19,36 @@ import (
 var (
 	checkIgnoreFail      bool
 	cfgLicense           string
+	cfgLicenseID         string
+	cfgHeaderMode        string
 	cfgInputs            []string
 	cfgSkips             []string
 	cfgPresetStyle       string
 	cfgLogLevel          string
 	cfgForceCommentStyle force.ForceCommentStyle
+	cfgHolder            string
+	cfgYear              string
+	cfgYearPolicy        string
+	cfgVars              map[string]string
+	cfgJobs              int
+	cfgSince             string
+	cfgStaged            bool
+	cfgTrackedOnly       bool
+	cfgNoGitignore       bool
+	cfgNoGitattributes   bool
+	cfgEncodings         []string
+	cfgGenerated         string
+	cfgGeneratedPatterns []string
+	cfgMaxSize           string
+	cfgMaxLines          int
+	cfgDryRun            bool
+	cfgDiff              bool
+	cfgPatch             string
+	cfgPatchFormat       string
+	cfgOutputDir         string
+	cfgCopyUnchanged     bool
+	cfgStateDir          string
+	cfgNoJournal         bool
 )
 
 // ExitError represents an error with an exit code
@@ -48,28 +73,54 @@ Exit Codes:
   2: Files have both content and header mismatchq
   3: Files have content mismatch
   4: Files have style mismatch
+  6: Files have outdated copyright years (--year-policy)
 `,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		// CLI validation errors should show usage
-		if cfgLicense == "" {
-			return fmt.Errorf("license file (--license) is required for check command")
+		if cfgLicense == "" && cfgLicenseID == "" && len(cfgRules) == 0 {
+			return fmt.Errorf("license file (--license) or --license-id is required for check command")
 		}
 		if cfgInputs == nil {
 			return fmt.Errorf("input pattern (--input) is required for check command")
 		}
 
+		logOutput, err := reportLogOutput(cmd)
+		if err != nil {
+			return err
+		}
+
 		// After validation passes, silence usage since any further errors are execution errors
 		cmd.SilenceUsage = true
 
 		// Create app config
 		appCfg := config.AppConfig{
-			LicenseFile: cfgLicense,
-			Inputs:      strings.Join(cfgInputs, ","),
-			Skips:       strings.Join(cfgSkips, ","),
-			HeaderStyle: cfgPresetStyle,
-			LogLevel:    logger.ParseLogLevel(cfgLogLevel),
-			IgnoreFail:  checkIgnoreFail,
-			IsPreCommit: false,
+			LicenseFile:       cfgLicense,
+			LicenseID:         cfgLicenseID,
+			HeaderMode:        cfgHeaderMode,
+			Inputs:            strings.Join(cfgInputs, ","),
+			Skips:             strings.Join(cfgSkips, ","),
+			Since:             cfgSince,
+			Staged:            cfgStaged,
+			TrackedOnly:       cfgTrackedOnly,
+			NoGitignore:       cfgNoGitignore,
+			NoGitattributes:   cfgNoGitattributes,
+			Encodings:         cfgEncodings,
+			GeneratedPolicy:   cfgGenerated,
+			GeneratedPatterns: cfgGeneratedPatterns,
+			MaxSize:           cfgMaxSize,
+			MaxLines:          cfgMaxLines,
+			HeaderStyle:       cfgPresetStyle,
+			Holder:            cfgHolder,
+			Year:              cfgYear,
+			YearPolicy:        cfgYearPolicy,
+			Vars:              cfgVars,
+			LogLevel:          logger.ParseLogLevel(cfgLogLevel),
+			LogOutput:         logOutput,
+			Jobs:              cfgJobs,
+			IgnoreFail:        checkIgnoreFail,
+			IsPreCommit:       false,
+			Rules:             cfgRules,
+			RuleDir:           cfgRuleDir,
 		}
 
 		cc.Init(&cc.Config{
@@ -91,6 +142,9 @@ Exit Codes:
 		// Create processor and run check
 		p := processor.NewFileProcessor(procCfg)
 		err = p.Check()
+		if reportErr := writeReport(cmd, p); reportErr != nil {
+			return reportErr
+		}
 
 		if err != nil {
 			if checkErr, ok := err.(*processor.CheckError); ok {
@@ -118,6 +172,11 @@ Exit Codes:
 						msg:  "license check failed: some files have incorrect license content and style",
 						Code: int(license.ContentAndStyleMismatch),
 					}
+				case license.OutdatedYear:
+					return &ExitError{
+						msg:  "license check failed: some files have outdated copyright years",
+						Code: int(license.OutdatedYear),
+					}
 				default:
 					return &ExitError{
 						msg:  "license check failed: unknown error",
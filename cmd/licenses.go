@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/jeeftor/license-manager/internal/licenses"
+	"github.com/spf13/cobra"
+)
+
+var licensesCmd = &cobra.Command{
+	Use:   "licenses",
+	Short: "List embedded licenses usable with --license-id",
+	Long:  `Display all licenses shipped with license-manager by SPDX identifier`,
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Println(color.CyanString("Available licenses:"))
+		fmt.Println()
+
+		for _, lic := range licenses.List() {
+			fmt.Printf("%s %s\n", color.BlueString("%-20s", lic.ID), lic.Name)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(licensesCmd)
+}
@@ -24,8 +24,13 @@ var preCommitCmd = &cobra.Command{
 	RunE: func(cmd *cobra.Command, args []string) error {
 		cmd.SilenceUsage = true
 
+		// An embedded license replaces the default license file
+		if cfgLicenseID != "" && !cmd.Flags().Changed("license") {
+			licensePath = ""
+		}
+
 		// Check if license file exists
-		if _, err := os.Stat(licensePath); os.IsNotExist(err) {
+		if _, err := os.Stat(licensePath); licensePath != "" && os.IsNotExist(err) {
 			return fmt.Errorf(`License file not found at: %s
 
 To specify a different license file, update your .pre-commit-config.yaml:
@@ -35,12 +40,19 @@ repos:
     rev: vX.X.X
     hooks:
       - id: license-manager
-        args: [--license, path/to/your/LICENSE]`, licensePath)
+        args: [--license, path/to/your/LICENSE]
+
+or use an embedded license with args: [--license-id, MIT]`, licensePath)
+		}
+
+		logOutput, err := reportLogOutput(cmd)
+		if err != nil {
+			return err
 		}
 
 		// Use files passed directly as arguments
 		if len(args) == 0 {
-			fmt.Println("No files to check")
+			fmt.Fprintln(logOutput, "No files to check")
 			return nil
 		}
 
@@ -48,16 +60,36 @@ repos:
 
 		// Rest of your existing code...
 		appCfg := config.AppConfig{
-			LicenseFile:  licensePath,
-			Inputs:       strings.Join(args, ","),
-			Skips:        ProcessPatterns(cfgSkips),
-			HeaderStyle:  cfgPresetStyle,
-			CommentStyle: "go", // default
-			LogLevel:     logger.ParseLogLevel(logLevel),
-			Interactive:  false,
-			Force:        false,
-			IgnoreFail:   false,
-			IsPreCommit:  true,
+			LicenseFile:       licensePath,
+			LicenseID:         cfgLicenseID,
+			HeaderMode:        cfgHeaderMode,
+			Inputs:            strings.Join(args, ","),
+			Skips:             ProcessPatterns(cfgSkips),
+			Since:             cfgSince,
+			Staged:            cfgStaged,
+			TrackedOnly:       cfgTrackedOnly,
+			NoGitignore:       cfgNoGitignore,
+			NoGitattributes:   cfgNoGitattributes,
+			Encodings:         cfgEncodings,
+			GeneratedPolicy:   cfgGenerated,
+			GeneratedPatterns: cfgGeneratedPatterns,
+			MaxSize:           cfgMaxSize,
+			MaxLines:          cfgMaxLines,
+			HeaderStyle:       cfgPresetStyle,
+			Holder:            cfgHolder,
+			Year:              cfgYear,
+			YearPolicy:        cfgYearPolicy,
+			Vars:              cfgVars,
+			CommentStyle:      "go", // default
+			LogLevel:          logger.ParseLogLevel(logLevel),
+			LogOutput:         logOutput,
+			Jobs:              cfgJobs,
+			Interactive:       false,
+			Force:             false,
+			IgnoreFail:        false,
+			IsPreCommit:       true,
+			Rules:             cfgRules,
+			RuleDir:           cfgRuleDir,
 		}
 
 		procCfg, err := appCfg.ToProcessorConfig()
@@ -80,7 +112,11 @@ repos:
 			}
 		}
 
-		return p.Check()
+		err = p.Check()
+		if reportErr := writeReport(cmd, p); reportErr != nil {
+			return reportErr
+		}
+		return err
 	},
 }
 
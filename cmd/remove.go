@@ -12,23 +12,59 @@ var removeCmd = &cobra.Command{
 	Short: "Remove license headers from files",
 	Long:  `Remove license headers from files that have them`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		logOutput, err := reportLogOutput(cmd)
+		if err != nil {
+			return err
+		}
+
 		appCfg := config.AppConfig{
 			// File paths
-			LicenseFile: cfgLicense, // Optional for remove command
-			Inputs:      ProcessPatterns(cfgInputs),
-			Skips:       ProcessPatterns(cfgSkips),
+			LicenseFile:       cfgLicense, // Optional for remove command
+			LicenseID:         cfgLicenseID,
+			HeaderMode:        cfgHeaderMode,
+			Inputs:            ProcessPatterns(cfgInputs),
+			Skips:             ProcessPatterns(cfgSkips),
+			Since:             cfgSince,
+			Staged:            cfgStaged,
+			TrackedOnly:       cfgTrackedOnly,
+			NoGitignore:       cfgNoGitignore,
+			NoGitattributes:   cfgNoGitattributes,
+			Encodings:         cfgEncodings,
+			GeneratedPolicy:   cfgGenerated,
+			GeneratedPatterns: cfgGeneratedPatterns,
+			MaxSize:           cfgMaxSize,
+			MaxLines:          cfgMaxLines,
 
 			// Style settings
 			HeaderStyle:       cfgPresetStyle,
 			CommentStyle:      "go", // default
 			ForceCommentStyle: cfgForceCommentStyle,
 
+			// License template values
+			Holder:     cfgHolder,
+			Year:       cfgYear,
+			YearPolicy: cfgYearPolicy,
+			Vars:       cfgVars,
+
 			// Behavior flags
-			LogLevel: logger.ParseLogLevel(cfgLogLevel),
+			LogLevel:      logger.ParseLogLevel(cfgLogLevel),
+			LogOutput:     logOutput,
+			Jobs:          cfgJobs,
+			DryRun:        cfgDryRun,
+			ShowDiff:      cfgDiff,
+			PatchFile:     cfgPatch,
+			PatchFormat:   cfgPatchFormat,
+			OutputDir:     cfgOutputDir,
+			CopyUnchanged: cfgCopyUnchanged,
+			StateDir:      stateDir(),
+			NoJournal:     cfgNoJournal,
 
 			Force:       false,
 			IgnoreFail:  false,
 			IsPreCommit: false,
+
+			Rules:   cfgRules,
+			RuleDir: cfgRuleDir,
 		}
 
 		procCfg, err := appCfg.ToProcessorConfig()
@@ -40,6 +76,9 @@ var removeCmd = &cobra.Command{
 		err = p.Remove()
 
 		cmd.SilenceUsage = true
+		if reportErr := writeReport(cmd, p); reportErr != nil {
+			return reportErr
+		}
 		return err
 	},
 }
@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/jeeftor/license-manager/internal/processor"
+	"github.com/jeeftor/license-manager/internal/report"
+	"github.com/spf13/cobra"
+)
+
+var (
+	cfgFormat   string
+	cfgOutput   string
+	cfgAnnotate string
+)
+
+// isCheckCommand reports whether a command produces check results
+func isCheckCommand(cmd *cobra.Command) bool {
+	return cmd.Name() == "check" || cmd.Name() == "pre-commit"
+}
+
+// reportToStdout reports whether a machine-readable report is written to stdout
+func reportToStdout() bool {
+	return cfgFormat != report.FormatText && (cfgOutput == "" || cfgOutput == "-")
+}
+
+// reportLogOutput validates --format and returns where log lines should go.
+// Stdout is kept free of log lines whenever a machine-readable format is selected.
+func reportLogOutput(cmd *cobra.Command) (io.Writer, error) {
+	if err := report.ValidateFormat(cfgFormat); err != nil {
+		return nil, err
+	}
+	if report.ForChecks(cfgFormat) && !isCheckCommand(cmd) {
+		return nil, fmt.Errorf("--format %s is only supported by the check and pre-commit commands", cfgFormat)
+	}
+
+	switch cfgAnnotate {
+	case "":
+	case report.AnnotateGitHub:
+		if !isCheckCommand(cmd) {
+			return nil, fmt.Errorf("--annotate is only supported by the check and pre-commit commands")
+		}
+		if reportToStdout() {
+			return nil, fmt.Errorf("--annotate %s writes to stdout, use --output for the %s report", cfgAnnotate, cfgFormat)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported annotation mode %q (supported: %s)", cfgAnnotate, report.AnnotateGitHub)
+	}
+
+	if cfgFormat == report.FormatText {
+		return os.Stdout, nil
+	}
+	return os.Stderr, nil
+}
+
+// writeReport writes the processor results in the selected format to --output or
+// stdout, and prints CI annotations when requested
+func writeReport(cmd *cobra.Command, p *processor.FileProcessor) error {
+	rep := p.Report(cmd.Name())
+	rep.Version = buildVersion
+
+	if cfgAnnotate == report.AnnotateGitHub {
+		if err := report.WriteGitHubAnnotations(os.Stdout, rep); err != nil {
+			return fmt.Errorf("failed to write annotations: %w", err)
+		}
+	}
+
+	if cfgFormat == report.FormatText {
+		return nil
+	}
+
+	var w io.Writer = os.Stdout
+	if cfgOutput != "" && cfgOutput != "-" {
+		f, err := os.Create(cfgOutput)
+		if err != nil {
+			return fmt.Errorf("failed to create report file: %w", err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	if err := report.Write(w, cfgFormat, rep); err != nil {
+		return fmt.Errorf("failed to write %s report: %w", cfgFormat, err)
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.PersistentFlags().
+		StringVar(&cfgFormat, "format", report.FormatText, "Report format (text, json, sarif, junit, checkstyle, gitlab)")
+	rootCmd.PersistentFlags().
+		StringVar(&cfgAnnotate, "annotate", "", "Print CI annotations for failed checks (github)")
+	rootCmd.PersistentFlags().
+		StringVarP(&cfgOutput, "output", "o", "", "Write the report to this file instead of stdout")
+}
@@ -2,14 +2,22 @@
 package cmd
 
 import (
+	"encoding/csv"
 	"fmt"
 	"os"
+	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/fatih/color"
+	"github.com/jeeftor/license-manager/internal/config"
 	"github.com/jeeftor/license-manager/internal/force"
+	"github.com/jeeftor/license-manager/internal/git"
+	"github.com/jeeftor/license-manager/internal/license"
 	"github.com/jeeftor/license-manager/internal/logger"
+	"github.com/jeeftor/license-manager/internal/processor"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 
 	cc "github.com/ivanpirog/coloredcobra"
@@ -19,6 +27,29 @@ const (
 	envPrefix = "LM"
 )
 
+var (
+	cfgFile    string
+	cfgFileErr error
+	cfgRules   []config.RuleConfig
+	cfgRuleDir string // Directory of the config file, rule paths are relative to it
+
+	// configPathKeys are settings holding a path, which are resolved relative to
+	// the config file. Lists of paths are marked true and resolved item by item.
+	configPathKeys = map[string]bool{
+		"license":   false,
+		"state-dir": false,
+		"input":     true,
+		"skip":      true,
+	}
+
+	// exclusiveFlags are flags that cannot be used together. A setting is not
+	// filled in when its partner comes from a layer with higher precedence.
+	exclusiveFlags = map[string]string{
+		"license":    "license-id",
+		"license-id": "license",
+	}
+)
+
 type commentStyleFlag struct {
 	value *force.ForceCommentStyle
 }
@@ -90,6 +121,17 @@ It can add, remove, update, and check license headers in multiple files using pa
   ` + color.CyanString(
 		"LM_LICENSE",
 	) + `   Path to license text file
+  ` + color.CyanString(
+		"LM_<FLAG>",
+	) + `    Any other flag, e.g. LM_LOG_LEVEL or LM_INPUT
+
+` + color.YellowString(
+		"Config file:",
+	) + `
+  A .license-manager.yaml (or .yml/.toml) file is searched for in the current
+  directory and its parents. Top-level keys are flag names; a section named
+  after a command (e.g. check:) applies to that command only.
+  Precedence: flags > environment > config file > defaults.
 `,
 }
 
@@ -174,6 +216,10 @@ Use "{{.CommandPath}} [command] --help" for more information about a command.{{e
 
 func init() {
 	cobra.OnInitialize(initConfig)
+	rootCmd.PersistentPreRunE = applyConfig
+
+	rootCmd.PersistentFlags().
+		StringVar(&cfgFile, "config", "", "Path to config file (default: search for .license-manager.yaml upwards)")
 
 	rootCmd.PersistentFlags().
 		StringVar(&cfgPresetStyle, "style", "hash", "Preset style for header/footer (run styles command for list)")
@@ -183,31 +229,323 @@ func init() {
 	cfgForceCommentStyle = force.No
 
 	rootCmd.PersistentFlags().StringVar(&cfgLicense, "license", "", "Path to license text file")
+	rootCmd.PersistentFlags().
+		StringVar(&cfgLicenseID, "license-id", "", "SPDX id of an embedded license to use instead of --license (run licenses command for list)")
+	rootCmd.PersistentFlags().
+		StringVar(&cfgHeaderMode, "header-mode", "full", "License header content: full (license text) or spdx (SPDX short-form tags)")
+	rootCmd.PersistentFlags().
+		StringVar(&cfgHolder, "holder", "", "Copyright holder for {{.Holder}} in license templates")
+	rootCmd.PersistentFlags().
+		StringVar(&cfgYear, "year", "", "Year for {{.Year}} in license templates (default: current year)")
+	rootCmd.PersistentFlags().StringVar(&cfgYearPolicy, "year-policy", license.YearAny,
+		"Year for {{.Year}}: any (current year, check accepts any year), current, first-commit or commit-range (from git log)")
+	rootCmd.PersistentFlags().
+		StringToStringVar(&cfgVars, "var", map[string]string{}, "Custom license template values (key=value) for {{.Vars.key}}")
 
 	rootCmd.PersistentFlags().
 		StringSliceVar(&cfgInputs, "input", []string{}, "Inputs file patterns")
 	rootCmd.PersistentFlags().StringSliceVar(&cfgSkips, "skip", []string{}, "Patterns to skip")
+	rootCmd.PersistentFlags().
+		StringVar(&cfgSince, "since", "", "Only process files changed since the merge base with this git ref")
+	rootCmd.PersistentFlags().BoolVar(&cfgStaged, "staged", false, "Only process files staged in git")
+	rootCmd.PersistentFlags().BoolVar(&cfgTrackedOnly, "tracked-only", false, "Only process files tracked by git")
+	rootCmd.PersistentFlags().
+		BoolVar(&cfgNoGitignore, "no-gitignore", false, "Do not exclude files listed in .gitignore (.licenseignore still applies)")
+	rootCmd.PersistentFlags().BoolVar(&cfgNoGitattributes, "no-gitattributes", false,
+		"Process files marked linguist-generated, linguist-vendored or binary in .gitattributes")
+	rootCmd.PersistentFlags().StringSliceVar(&cfgEncodings, "encoding", []string{},
+		"Encoding of files without a byte order mark, as pattern=encoding (utf-8, latin-1, windows-1252, shift-jis, utf-16le, utf-16be)")
+	rootCmd.PersistentFlags().StringVar(&cfgGenerated, "generated", processor.GeneratedSkip,
+		"Generated code: skip, check (check but never change it) or process (like any other file)")
+	rootCmd.PersistentFlags().StringArrayVar(&cfgGeneratedPatterns, "generated-pattern", []string{},
+		"Extra generated-code marker as language=regexp, matched against comment text (language * for all)")
+	rootCmd.PersistentFlags().
+		StringVar(&cfgMaxSize, "max-size", "0", "Skip files larger than this size (e.g. 512KB, 2MB), 0 for no limit")
+	rootCmd.PersistentFlags().IntVar(&cfgMaxLines, "max-lines", 0, "Skip files with more lines than this, 0 for no limit")
 
 	rootCmd.PersistentFlags().
 		StringVar(&cfgLogLevel, "log-level", "notice", "Log level (debug, info, notice, warn, error)")
+	rootCmd.PersistentFlags().
+		IntVarP(&cfgJobs, "jobs", "j", 0, "Number of files processed in parallel (default: number of CPUs)")
+	rootCmd.PersistentFlags().
+		BoolVar(&cfgDryRun, "dry-run", false, "Show which files would change without writing them")
+	rootCmd.PersistentFlags().
+		BoolVar(&cfgDiff, "diff", false, "Print a unified diff of every change to files (add, update, remove, bump-year)")
+	rootCmd.PersistentFlags().
+		StringVar(&cfgPatch, "patch", "", "Write changes to this patch file instead of modifying files (add, update, remove, bump-year)")
+	rootCmd.PersistentFlags().StringVar(&cfgPatchFormat, "patch-format", processor.PatchFormatPatch,
+		"Patch file format: patch (git apply) or mbox (git am)")
+	rootCmd.PersistentFlags().StringVar(&cfgOutputDir, "output-dir", "",
+		"Write changed files to the same relative path under this directory instead of in place (add, update, remove, bump-year)")
+	rootCmd.PersistentFlags().
+		BoolVar(&cfgCopyUnchanged, "copy-unchanged", false, "Also copy processed files without changes to --output-dir")
+	rootCmd.PersistentFlags().
+		StringVar(&cfgStateDir, "state-dir", "", "Directory of the undo journal (default: license-manager in the git directory)")
+	rootCmd.PersistentFlags().
+		BoolVar(&cfgNoJournal, "no-journal", false, "Do not record changed files for the undo command")
 }
 
 func initConfig() {
 	viper.SetEnvPrefix(envPrefix)
-	viper.AutomaticEnv()
 	viper.SetEnvKeyReplacer(strings.NewReplacer("-", "_"))
 
-	// Update variables with viper values
-	if viper.IsSet("license") {
-		cfgLicense = viper.GetString("license")
+	if cfgFile == "" {
+		cfgFile = os.Getenv(envName("config"))
+	}
+	if cfgFile == "" {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return
+		}
+		found, err := config.FindFile(cwd)
+		if err != nil || found == "" {
+			return
+		}
+		cfgFile = found
 	}
 
-	if viper.IsSet("log-level") {
-		cfgLogLevel = viper.GetString("log-level")
+	viper.SetConfigFile(cfgFile)
+	if err := viper.ReadInConfig(); err != nil {
+		cfgFileErr = fmt.Errorf("failed to read config file %s: %w", cfgFile, err)
 	}
-	if viper.IsSet("style") {
-		cfgPresetStyle = viper.GetString("style")
+}
+
+// applyConfig fills every flag the user did not pass on the command line.
+// Precedence is: flag > LM_* environment variable > config file > flag default.
+// Inside the config file a section named after the command (e.g. "check:")
+// overrides the top-level keys for that command only.
+func applyConfig(cmd *cobra.Command, args []string) error {
+	if cfgFileErr != nil {
+		return cfgFileErr
+	}
+
+	var errs []string
+	cmd.Flags().VisitAll(func(f *pflag.Flag) {
+		if f.Changed || f.Name == "help" || f.Name == "config" {
+			return
+		}
+		if partner, ok := exclusiveFlags[f.Name]; ok && settingLayer(cmd, partner) > settingLayer(cmd, f.Name) {
+			return
+		}
+
+		if items, ok := f.Value.(pflag.SliceValue); ok && f.Value.Type() == "stringArray" {
+			values, source, found := lookupItems(cmd.Name(), f.Name)
+			if !found {
+				return
+			}
+			if err := items.Replace(values); err != nil {
+				errs = append(errs, fmt.Sprintf("invalid value %q for %s from %s: %v", values, f.Name, source, err))
+			}
+			return
+		}
+
+		value, source, ok := lookupSetting(cmd.Name(), f.Name)
+		if !ok {
+			return
+		}
+
+		if err := f.Value.Set(value); err != nil {
+			errs = append(errs, fmt.Sprintf("invalid value %q for %s from %s: %v", value, f.Name, source, err))
+		}
+	})
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "\n"))
+	}
+
+	return loadRules(cmd.Name())
+}
+
+// stateDir returns the directory of the undo journal. By default it lives in the
+// git directory, so it is found from any directory of the checkout, and outside
+// git next to the config file or in the working directory.
+func stateDir() string {
+	if cfgStateDir != "" {
+		return cfgStateDir
+	}
+	if dir, err := git.CommonDir("."); err == nil {
+		return filepath.Join(dir, "license-manager")
 	}
+	return filepath.Join(cfgRuleDir, ".license-manager")
+}
+
+// loadRules reads the per-path rules from the config file, preferring a command section
+func loadRules(command string) error {
+	cfgRules, cfgRuleDir = nil, ""
+	if viper.ConfigFileUsed() == "" {
+		return nil
+	}
+	if abs, err := filepath.Abs(viper.ConfigFileUsed()); err == nil {
+		cfgRuleDir = filepath.Dir(abs)
+	}
+
+	for _, key := range []string{command + ".rules", "rules"} {
+		if !viper.IsSet(key) {
+			continue
+		}
+		if err := viper.UnmarshalKey(key, &cfgRules); err != nil {
+			return fmt.Errorf("invalid rules in %s: %w", viper.ConfigFileUsed(), err)
+		}
+		break
+	}
+
+	for i := range cfgRules {
+		if cfgRules[i].License != "" && !filepath.IsAbs(cfgRules[i].License) {
+			cfgRules[i].License = filepath.Join(cfgRuleDir, cfgRules[i].License)
+		}
+	}
+	return nil
+}
+
+// settingLayer returns where a setting comes from: 3 for a command line flag,
+// 2 for the environment, 1 for the config file and 0 when it is not set
+func settingLayer(cmd *cobra.Command, name string) int {
+	if cmd.Flags().Changed(name) {
+		return 3
+	}
+	if _, ok := os.LookupEnv(envName(name)); ok {
+		return 2
+	}
+	if viper.ConfigFileUsed() != "" && (viper.IsSet(cmd.Name()+"."+name) || viper.IsSet(name)) {
+		return 1
+	}
+	return 0
+}
+
+// lookupSetting returns the value for a flag from the environment or the config file
+func lookupSetting(command, name string) (string, string, bool) {
+	if value, ok := os.LookupEnv(envName(name)); ok {
+		return value, envName(name), true
+	}
+
+	if viper.ConfigFileUsed() == "" {
+		return "", "", false
+	}
+
+	for _, key := range []string{command + "." + name, name} {
+		if !viper.IsSet(key) {
+			continue
+		}
+		value := viper.Get(key)
+		if list, ok := configPathKeys[name]; ok {
+			value = resolveConfigPaths(value, list)
+		}
+		return settingString(value), viper.ConfigFileUsed(), true
+	}
+
+	return "", "", false
+}
+
+// resolveConfigPaths makes relative paths from the config file relative to its
+// directory, so the same files are found from any working directory
+func resolveConfigPaths(value interface{}, list bool) interface{} {
+	resolve := func(path string) string {
+		path = strings.TrimSpace(path)
+		if path == "" || filepath.IsAbs(path) {
+			return path
+		}
+		return filepath.Join(filepath.Dir(viper.ConfigFileUsed()), path)
+	}
+
+	switch v := value.(type) {
+	case []interface{}:
+		paths := make([]string, 0, len(v))
+		for _, item := range v {
+			paths = append(paths, resolve(fmt.Sprint(item)))
+		}
+		return paths
+	case string:
+		if !list {
+			return resolve(v)
+		}
+		items, err := csv.NewReader(strings.NewReader(v)).Read()
+		if err != nil {
+			return v
+		}
+		paths := make([]string, 0, len(items))
+		for _, item := range items {
+			paths = append(paths, resolve(item))
+		}
+		return paths
+	default:
+		return value
+	}
+}
+
+// lookupItems is lookupSetting for array flags, which take each item as it is
+// instead of splitting it at commas. A list in the config file gives one item
+// per entry, the environment a single item.
+func lookupItems(command, name string) ([]string, string, bool) {
+	if value, ok := os.LookupEnv(envName(name)); ok {
+		return []string{value}, envName(name), true
+	}
+
+	if viper.ConfigFileUsed() == "" {
+		return nil, "", false
+	}
+
+	for _, key := range []string{command + "." + name, name} {
+		if !viper.IsSet(key) {
+			continue
+		}
+		var items []string
+		switch v := viper.Get(key).(type) {
+		case []interface{}:
+			for _, item := range v {
+				items = append(items, fmt.Sprint(item))
+			}
+		default:
+			items = []string{fmt.Sprint(v)}
+		}
+		return items, viper.ConfigFileUsed(), true
+	}
+
+	return nil, "", false
+}
+
+// csvField quotes a list item for the CSV parsing of slice flags, so items
+// such as "*.{js,ts}" may contain commas
+func csvField(item string) string {
+	if !strings.ContainsAny(item, ",\"\n") {
+		return item
+	}
+	return `"` + strings.ReplaceAll(item, `"`, `""`) + `"`
+}
+
+// settingString converts a config file value into the string form a flag accepts
+func settingString(value interface{}) string {
+	switch v := value.(type) {
+	case []interface{}:
+		parts := make([]string, 0, len(v))
+		for _, item := range v {
+			parts = append(parts, csvField(fmt.Sprint(item)))
+		}
+		return strings.Join(parts, ",")
+	case []string:
+		parts := make([]string, 0, len(v))
+		for _, item := range v {
+			parts = append(parts, csvField(item))
+		}
+		return strings.Join(parts, ",")
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		parts := make([]string, 0, len(keys))
+		for _, key := range keys {
+			parts = append(parts, fmt.Sprintf("%s=%v", key, v[key]))
+		}
+		return strings.Join(parts, ",")
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// envName returns the environment variable name for a flag (e.g. log-level -> LM_LOG_LEVEL)
+func envName(flag string) string {
+	return envPrefix + "_" + strings.ToUpper(strings.ReplaceAll(flag, "-", "_"))
 }
 
 func ProcessPatterns(patterns []string) string {
@@ -0,0 +1,238 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/jeeftor/license-manager/internal/git"
+	"github.com/jeeftor/license-manager/internal/logger"
+	"github.com/jeeftor/license-manager/internal/processor"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// newConfigTestCommand returns a command with a few flags and, when config is
+// not empty, loads it as the config file
+func newConfigTestCommand(t *testing.T, name, config string) (*cobra.Command, string) {
+	t.Helper()
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+	cfgFileErr = nil
+
+	dir := t.TempDir()
+	if config != "" {
+		path := filepath.Join(dir, ".license-manager.yaml")
+		if err := os.WriteFile(path, []byte(config), 0644); err != nil {
+			t.Fatal(err)
+		}
+		viper.SetConfigFile(path)
+		if err := viper.ReadInConfig(); err != nil {
+			t.Fatalf("ReadInConfig() failed: %v", err)
+		}
+	}
+
+	cmd := &cobra.Command{Use: name}
+	cmd.Flags().String("style", "hash", "")
+	cmd.Flags().String("license", "", "")
+	cmd.Flags().String("license-id", "", "")
+	cmd.Flags().StringSlice("skip", nil, "")
+	return cmd, dir
+}
+
+func TestApplyConfigPrecedence(t *testing.T) {
+	tests := []struct {
+		name    string
+		command string
+		config  string
+		env     map[string]string
+		args    []string
+		want    string
+	}{
+		{"default", "check", "", nil, nil, "hash"},
+		{"top-level key", "check", "style: box\n", nil, nil, "box"},
+		{"command section", "check", "style: box\ncheck:\n  style: brackets\n", nil, nil, "brackets"},
+		{"other command section", "add", "style: box\ncheck:\n  style: brackets\n", nil, nil, "box"},
+		{"environment", "check", "style: box\ncheck:\n  style: brackets\n", map[string]string{"LM_STYLE": "stars"}, nil, "stars"},
+		{"flag", "check", "check:\n  style: brackets\n", map[string]string{"LM_STYLE": "stars"}, []string{"--style", "slashes"}, "slashes"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+			cmd, _ := newConfigTestCommand(t, tt.command, tt.config)
+			if err := cmd.ParseFlags(tt.args); err != nil {
+				t.Fatal(err)
+			}
+			if err := applyConfig(cmd, nil); err != nil {
+				t.Fatalf("applyConfig() failed: %v", err)
+			}
+			if got, _ := cmd.Flags().GetString("style"); got != tt.want {
+				t.Errorf("style = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyConfigPaths(t *testing.T) {
+	cmd, dir := newConfigTestCommand(t, "check", "license: LICENSE.txt\nskip:\n  - vendor/**\n  - \"a,b\"\n")
+	if err := applyConfig(cmd, nil); err != nil {
+		t.Fatalf("applyConfig() failed: %v", err)
+	}
+
+	// Paths in the config file are relative to the config file
+	if got, _ := cmd.Flags().GetString("license"); got != filepath.Join(dir, "LICENSE.txt") {
+		t.Errorf("license = %q, want it resolved against %s", got, dir)
+	}
+	wantSkip := []string{filepath.Join(dir, "vendor/**"), filepath.Join(dir, "a,b")}
+	if got, _ := cmd.Flags().GetStringSlice("skip"); !reflect.DeepEqual(got, wantSkip) {
+		t.Errorf("skip = %q, want %q", got, wantSkip)
+	}
+
+	// Paths from the environment are used as they are
+	t.Setenv("LM_LICENSE", "other/LICENSE")
+	cmd, _ = newConfigTestCommand(t, "check", "license: LICENSE.txt\n")
+	if err := applyConfig(cmd, nil); err != nil {
+		t.Fatalf("applyConfig() failed: %v", err)
+	}
+	if got, _ := cmd.Flags().GetString("license"); got != "other/LICENSE" {
+		t.Errorf("license = %q, want other/LICENSE", got)
+	}
+}
+
+// TestConfigPathsFromSubdirectory verifies that running from a subdirectory
+// scans and skips the same files as running next to the config file
+func TestConfigPathsFromSubdirectory(t *testing.T) {
+	cmd, dir := newConfigTestCommand(t, "check", "input: \"**/*.go\"\nskip:\n  - vendor/**\n")
+	cmd.Flags().StringSlice("input", nil, "")
+	for _, name := range []string{"main.go", "sub/sub.go", "vendor/dep.go"} {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("package main\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(filepath.Join(dir, "sub")); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	if err := applyConfig(cmd, nil); err != nil {
+		t.Fatalf("applyConfig() failed: %v", err)
+	}
+	inputs, _ := cmd.Flags().GetStringSlice("input")
+	skips, _ := cmd.Flags().GetStringSlice("skip")
+	files, err := processor.NewFileProcessor(&processor.Config{
+		Input:    ProcessPatterns(inputs),
+		Skip:     ProcessPatterns(skips),
+		LogLevel: logger.ErrorLevel,
+	}).PrepareOperation()
+	if err != nil {
+		t.Fatalf("PrepareOperation() failed: %v", err)
+	}
+
+	want := []string{filepath.Join(dir, "main.go"), filepath.Join(dir, "sub", "sub.go")}
+	if !reflect.DeepEqual(files, want) {
+		t.Errorf("Selected %q, want %q", files, want)
+	}
+}
+
+func TestApplyConfigInvalidValue(t *testing.T) {
+	cmd, _ := newConfigTestCommand(t, "check", "style: box\n")
+	cmd.Flags().Int("jobs", 0, "")
+	t.Setenv("LM_JOBS", "many")
+	if err := applyConfig(cmd, nil); err == nil {
+		t.Error("applyConfig() accepted a non-numeric jobs value")
+	}
+}
+
+func TestApplyConfigExclusiveLicense(t *testing.T) {
+	tests := []struct {
+		name          string
+		config        string
+		env           map[string]string
+		args          []string
+		wantLicense   bool
+		wantLicenseID bool
+	}{
+		{"flag id over config file", "license: LICENSE\n", nil, []string{"--license-id", "MIT"}, false, true},
+		{"flag file over config id", "license-id: MIT\n", nil, []string{"--license", "LICENSE"}, true, false},
+		{"environment id over config file", "license: LICENSE\n", map[string]string{"LM_LICENSE_ID": "MIT"}, nil, false, true},
+		{"flag file over environment id", "", map[string]string{"LM_LICENSE_ID": "MIT"}, []string{"--license", "LICENSE"}, true, false},
+		{"both in config file", "license: LICENSE\nlicense-id: MIT\n", nil, nil, true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+			cmd, _ := newConfigTestCommand(t, "check", tt.config)
+			if err := cmd.ParseFlags(tt.args); err != nil {
+				t.Fatal(err)
+			}
+			if err := applyConfig(cmd, nil); err != nil {
+				t.Fatalf("applyConfig() failed: %v", err)
+			}
+			licenseFile, _ := cmd.Flags().GetString("license")
+			licenseID, _ := cmd.Flags().GetString("license-id")
+			if (licenseFile != "") != tt.wantLicense || (licenseID != "") != tt.wantLicenseID {
+				t.Errorf("license = %q, license-id = %q", licenseFile, licenseID)
+			}
+		})
+	}
+}
+
+func TestStateDir(t *testing.T) {
+	defer func(dir, ruleDir string) { cfgStateDir, cfgRuleDir = dir, ruleDir }(cfgStateDir, cfgRuleDir)
+
+	cfgStateDir = "custom"
+	if got := stateDir(); got != "custom" {
+		t.Errorf("stateDir() = %q, want the --state-dir value", got)
+	}
+
+	// Without the flag the journal follows the repository, not the working directory
+	cfgStateDir = ""
+	gitDir, err := git.CommonDir(".")
+	if err != nil {
+		t.Skipf("not in a git checkout: %v", err)
+	}
+	if got := stateDir(); got != filepath.Join(gitDir, "license-manager") {
+		t.Errorf("stateDir() = %q, want it in %s", got, gitDir)
+	}
+}
+
+func TestApplyConfigArrayFlag(t *testing.T) {
+	config := "generated-pattern:\n  - \"go=^Code generated by (foo|bar), do not edit\"\n  - \"*=DO NOT EDIT\"\n"
+	cmd, _ := newConfigTestCommand(t, "check", config)
+	cmd.Flags().StringArray("generated-pattern", nil, "")
+	if err := applyConfig(cmd, nil); err != nil {
+		t.Fatalf("applyConfig() failed: %v", err)
+	}
+
+	// Regular expressions keep their commas
+	want := []string{"go=^Code generated by (foo|bar), do not edit", "*=DO NOT EDIT"}
+	if got, _ := cmd.Flags().GetStringArray("generated-pattern"); !reflect.DeepEqual(got, want) {
+		t.Errorf("generated-pattern = %q, want %q", got, want)
+	}
+
+	t.Setenv("LM_GENERATED_PATTERN", "*=a{1,3}")
+	cmd, _ = newConfigTestCommand(t, "check", config)
+	cmd.Flags().StringArray("generated-pattern", nil, "")
+	if err := applyConfig(cmd, nil); err != nil {
+		t.Fatalf("applyConfig() failed: %v", err)
+	}
+	if got, _ := cmd.Flags().GetStringArray("generated-pattern"); !reflect.DeepEqual(got, []string{"*=a{1,3}"}) {
+		t.Errorf("generated-pattern = %q, want the environment value", got)
+	}
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/jeeftor/license-manager/internal/config"
+	"github.com/jeeftor/license-manager/internal/logger"
+	"github.com/jeeftor/license-manager/internal/processor"
+	"github.com/spf13/cobra"
+)
+
+var (
+	undoList  bool
+	undoForce bool
+)
+
+var undoCmd = &cobra.Command{
+	Use:   "undo [run-id]",
+	Short: "Revert the files changed by a previous run",
+	Long: `Restore the files changed by the last add, update, remove or bump-year run,
+or by the run given as argument, from the undo journal in --state-dir.
+
+Files that changed since the run are not overwritten unless --force is given.
+With --dry-run the files are only listed. Use --list to show the recorded runs.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		logOutput, err := reportLogOutput(cmd)
+		if err != nil {
+			return err
+		}
+
+		appCfg := config.AppConfig{
+			DryRun:    cfgDryRun,
+			PatchFile: cfgPatch,
+			OutputDir: cfgOutputDir,
+			StateDir:  stateDir(),
+			LogLevel:  logger.ParseLogLevel(cfgLogLevel),
+			LogOutput: logOutput,
+		}
+
+		procCfg, err := appCfg.ToProcessorConfig()
+		if err != nil {
+			return err
+		}
+
+		p := processor.NewFileProcessor(procCfg)
+		cmd.SilenceUsage = true
+
+		if undoList {
+			runs, err := p.Runs()
+			if err != nil {
+				return err
+			}
+			if len(runs) == 0 {
+				fmt.Fprintf(logOutput, "No runs recorded in %s\n", appCfg.StateDir)
+			}
+			for _, run := range runs {
+				fmt.Fprintf(logOutput, "%-20s %-9s %3d files  %s\n",
+					run.ID, run.Command, len(run.Files), run.Created.Local().Format("2006-01-02 15:04:05"))
+			}
+			return nil
+		}
+
+		id := ""
+		if len(args) == 1 {
+			id = args[0]
+		}
+		err = p.Undo(id, undoForce)
+		if reportErr := writeReport(cmd, p); reportErr != nil {
+			return reportErr
+		}
+		return err
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(undoCmd)
+
+	undoCmd.Flags().BoolVar(&undoList, "list", false, "List the runs that can be undone, oldest first")
+	undoCmd.Flags().BoolVar(&undoForce, "force", false, "Restore files even if they changed since the run")
+}
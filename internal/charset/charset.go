@@ -0,0 +1,175 @@
+// Package charset detects the character encoding of source files and converts
+// them to and from UTF-8
+package charset
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/jeeftor/license-manager/internal/errors"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/unicode"
+)
+
+// Supported encodings
+const (
+	UTF8        = "utf-8"
+	UTF16LE     = "utf-16le"
+	UTF16BE     = "utf-16be"
+	Latin1      = "latin-1" // ISO-8859-1
+	Windows1252 = "windows-1252"
+	ShiftJIS    = "shift-jis"
+)
+
+// aliases maps other common spellings to the supported names
+var aliases = map[string]string{
+	"utf8":        UTF8,
+	"utf16le":     UTF16LE,
+	"utf16be":     UTF16BE,
+	"latin1":      Latin1,
+	"iso88591":    Latin1,
+	"windows1252": Windows1252,
+	"cp1252":      Windows1252,
+	"shiftjis":    ShiftJIS,
+	"sjis":        ShiftJIS,
+}
+
+// Byte order marks
+var (
+	bomUTF8    = []byte{0xEF, 0xBB, 0xBF}
+	bomUTF16LE = []byte{0xFF, 0xFE}
+	bomUTF16BE = []byte{0xFE, 0xFF}
+	bomUTF32BE = []byte{0x00, 0x00, 0xFE, 0xFF}
+)
+
+// Names lists the supported encodings
+func Names() []string {
+	return []string{UTF8, UTF16LE, UTF16BE, Latin1, Windows1252, ShiftJIS}
+}
+
+// Lookup returns the supported name of an encoding, accepting common aliases
+// such as "latin1", "cp1252" or "sjis"
+func Lookup(name string) (string, error) {
+	key := strings.NewReplacer("-", "", "_", "").Replace(strings.ToLower(strings.TrimSpace(name)))
+	if canonical, ok := aliases[key]; ok {
+		return canonical, nil
+	}
+	return "", errors.NewValidationError(
+		fmt.Sprintf("unsupported encoding %q (supported: %s)", name, strings.Join(Names(), ", ")),
+		"Encoding")
+}
+
+// Encoding is the encoding of one file
+type Encoding struct {
+	Name string // One of the supported encodings
+	BOM  bool   // UTF-16 with a byte order mark, a UTF-8 mark is kept in the text
+}
+
+// String returns the name of the encoding
+func (e Encoding) String() string {
+	if e.BOM && e.Name != UTF8 {
+		return e.Name + " with BOM"
+	}
+	return e.Name
+}
+
+// Unicode reports whether the encoding can represent every character
+func (e Encoding) Unicode() bool {
+	return e.Name == UTF8 || e.Name == UTF16LE || e.Name == UTF16BE
+}
+
+// LineBased reports whether every line ends in a single \n byte, so that line
+// based tools such as diff and git apply can handle the encoded text
+func (e Encoding) LineBased() bool {
+	return e.Name != UTF16LE && e.Name != UTF16BE
+}
+
+// Detect returns the encoding of data. A byte order mark wins, then the declared
+// encoding, if any, then UTF-8. Data that is none of these is refused, so that
+// files in unknown encodings are never rewritten.
+func Detect(data []byte, declared string) (Encoding, error) {
+	switch {
+	case bytes.HasPrefix(data, bomUTF32BE):
+		return Encoding{}, fmt.Errorf("UTF-32 is not supported")
+	case bytes.HasPrefix(data, bomUTF8):
+		return Encoding{Name: UTF8}, nil
+	case bytes.HasPrefix(data, bomUTF16LE):
+		return Encoding{Name: UTF16LE, BOM: true}, nil
+	case bytes.HasPrefix(data, bomUTF16BE):
+		return Encoding{Name: UTF16BE, BOM: true}, nil
+	}
+
+	if declared != "" {
+		name, err := Lookup(declared)
+		if err != nil {
+			return Encoding{}, err
+		}
+		return Encoding{Name: name}, nil
+	}
+
+	if bytes.IndexByte(data, 0) >= 0 {
+		return Encoding{}, fmt.Errorf("NUL bytes without a byte order mark, declare the encoding if this is UTF-16")
+	}
+	if !utf8.Valid(data) {
+		return Encoding{}, fmt.Errorf("not valid UTF-8, declare the encoding of this file")
+	}
+	return Encoding{Name: UTF8}, nil
+}
+
+// codec returns the x/text encoding, nil for UTF-8
+func (e Encoding) codec() encoding.Encoding {
+	bom := unicode.IgnoreBOM
+	if e.BOM {
+		bom = unicode.UseBOM
+	}
+	switch e.Name {
+	case UTF16LE:
+		return unicode.UTF16(unicode.LittleEndian, bom)
+	case UTF16BE:
+		return unicode.UTF16(unicode.BigEndian, bom)
+	case Latin1:
+		return charmap.ISO8859_1
+	case Windows1252:
+		return charmap.Windows1252
+	case ShiftJIS:
+		return japanese.ShiftJIS
+	}
+	return nil
+}
+
+// Decode converts data to UTF-8 text. It fails when the text would not encode
+// back to the same bytes, as writing it would then damage the file.
+func (e Encoding) Decode(data []byte) (string, error) {
+	codec := e.codec()
+	if codec == nil {
+		return string(data), nil
+	}
+
+	text, err := codec.NewDecoder().Bytes(data)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s: %v", e, err)
+	}
+	if again, err := codec.NewEncoder().Bytes(text); err != nil || !bytes.Equal(again, data) {
+		return "", fmt.Errorf("content does not convert losslessly from %s", e)
+	}
+	return string(text), nil
+}
+
+// Encode converts UTF-8 text to the encoding. It fails when the text has
+// characters the encoding cannot represent.
+func (e Encoding) Encode(text string) ([]byte, error) {
+	codec := e.codec()
+	if codec == nil {
+		return []byte(text), nil
+	}
+
+	data, err := codec.NewEncoder().Bytes([]byte(text))
+	if err != nil {
+		return nil, fmt.Errorf("text cannot be written as %s: %v", e, err)
+	}
+	return data, nil
+}
@@ -0,0 +1,85 @@
+package charset
+
+import "testing"
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		declared string
+		want     Encoding
+		wantErr  bool
+	}{
+		{"ascii", "int x;\n", "", Encoding{Name: UTF8}, false},
+		{"utf-8", "café\n", "", Encoding{Name: UTF8}, false},
+		{"utf-8 bom", "\xef\xbb\xbfx\n", "", Encoding{Name: UTF8}, false},
+		{"utf-16le bom", "\xff\xfex\x00", "", Encoding{Name: UTF16LE, BOM: true}, false},
+		{"utf-16be bom", "\xfe\xff\x00x", "", Encoding{Name: UTF16BE, BOM: true}, false},
+		{"bom beats declared", "\xff\xfex\x00", "latin-1", Encoding{Name: UTF16LE, BOM: true}, false},
+		{"declared", "caf\xe9\n", "cp1252", Encoding{Name: Windows1252}, false},
+		{"undeclared latin-1", "caf\xe9\n", "", Encoding{}, true},
+		{"utf-16 without bom", "x\x00\n\x00", "", Encoding{}, true},
+		{"utf-32", "\x00\x00\xfe\xff\x00\x00\x00x", "", Encoding{}, true},
+		{"unknown declared", "x\n", "ebcdic", Encoding{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Detect([]byte(tt.data), tt.declared)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Detect() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Detect() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		enc  Encoding
+		data string
+		text string
+	}{
+		{"utf-16le", Encoding{Name: UTF16LE, BOM: true}, "\xff\xfec\x00a\x00f\x00\xe9\x00\n\x00", "café\n"},
+		{"utf-16be", Encoding{Name: UTF16BE, BOM: true}, "\xfe\xff\x00c\x00a\x00f\x00\xe9\x00\n", "café\n"},
+		{"latin-1", Encoding{Name: Latin1}, "caf\xe9\n", "café\n"},
+		{"windows-1252", Encoding{Name: Windows1252}, "\x80 5\n", "€ 5\n"},
+		{"shift-jis", Encoding{Name: ShiftJIS}, "\x93\xfa\x96\x7b\n", "日本\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, err := tt.enc.Decode([]byte(tt.data))
+			if err != nil || text != tt.text {
+				t.Fatalf("Decode() = %q, %v, want %q", text, err, tt.text)
+			}
+			data, err := tt.enc.Encode(text)
+			if err != nil || string(data) != tt.data {
+				t.Errorf("Encode() = %q, %v, want %q", data, err, tt.data)
+			}
+		})
+	}
+}
+
+func TestEncodeUnrepresentable(t *testing.T) {
+	if _, err := (Encoding{Name: Latin1}).Encode("日本"); err == nil {
+		t.Error("Encode() of Japanese text as latin-1 succeeded")
+	}
+	if _, err := (Encoding{Name: Windows1252}).Decode([]byte("\x81")); err == nil {
+		t.Error("Decode() of a byte undefined in windows-1252 succeeded")
+	}
+}
+
+func TestLookup(t *testing.T) {
+	for name, want := range map[string]string{"UTF-8": UTF8, "ISO-8859-1": Latin1, "cp1252": Windows1252, "Shift_JIS": ShiftJIS} {
+		if got, err := Lookup(name); err != nil || got != want {
+			t.Errorf("Lookup(%q) = %q, %v, want %q", name, got, err, want)
+		}
+	}
+	if _, err := Lookup("koi8-r"); err == nil {
+		t.Error("Lookup() accepted an unsupported encoding")
+	}
+}
@@ -2,11 +2,19 @@
 package config
 
 import (
+	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
 
+	"github.com/jeeftor/license-manager/internal/charset"
 	"github.com/jeeftor/license-manager/internal/errors"
 	"github.com/jeeftor/license-manager/internal/force"
+	"github.com/jeeftor/license-manager/internal/license"
+	"github.com/jeeftor/license-manager/internal/licenses"
 	"github.com/jeeftor/license-manager/internal/logger"
 	"github.com/jeeftor/license-manager/internal/processor"
 )
@@ -15,13 +23,43 @@ import (
 type AppConfig struct {
 	// File paths
 	LicenseFile string // Path to license template file
+	LicenseID   string // SPDX identifier of an embedded license, used instead of LicenseFile
 	Inputs      string // Inputs file patterns
 	Skips       string // Skips patterns
+	HeaderMode  string // "full" writes the license text, "spdx" writes SPDX short-form tags
+
+	// File selection
+	Since             string   // Only files changed since this git ref
+	Staged            bool     // Only files staged in git
+	TrackedOnly       bool     // Only files tracked by git
+	NoGitignore       bool     // Do not exclude files listed in .gitignore
+	NoGitattributes   bool     // Process files marked generated, vendored or binary in .gitattributes
+	Encodings         []string // Declared encodings of files without a BOM, as pattern=encoding
+	GeneratedPolicy   string   // One of the processor.Generated* policies
+	GeneratedPatterns []string // Extra generated-code markers, as language=regexp
+	MaxSize           string   // Skip files larger than this, e.g. "1MB", "0" for no limit
+	MaxLines          int      // Skip files with more lines, 0 for no limit
+
+	// License template values
+	Holder     string            // Copyright holder for {{.Holder}}
+	Year       string            // Year for {{.Year}}, defaults to the current year
+	YearPolicy string            // One of the license.Year* policies, license.YearAny when empty
+	Vars       map[string]string // Custom values for {{.Vars.name}}
 
 	// UI/Behavior settings
-	LogLevel    logger.LogLevel
-	Interactive bool
-	Force       bool
+	LogLevel      logger.LogLevel
+	LogOutput     io.Writer // Destination of log lines, defaults to stdout
+	Interactive   bool
+	Jobs          int    // Files processed in parallel, 0 uses all CPUs
+	DryRun        bool   // Report changes without writing them
+	ShowDiff      bool   // Print a unified diff of every change
+	PatchFile     string // Write changes to this patch file instead of the files
+	PatchFormat   string // "patch" (default) or "mbox"
+	OutputDir     string // Write changed files under this directory instead of in place
+	CopyUnchanged bool   // Also copy files without changes to OutputDir
+	StateDir      string // Directory of the undo journal
+	NoJournal     bool   // Do not record runs for undo
+	Force         bool
 
 	// Style preferences
 	HeaderStyle       string
@@ -30,8 +68,28 @@ type AppConfig struct {
 	IgnoreFail        bool
 	ForceCommentStyle force.ForceCommentStyle
 	IsPreCommit       bool
+
+	// Per-path overrides from the config file
+	Rules   []RuleConfig
+	RuleDir string // Directory rule paths are relative to, the working directory when empty
 }
 
+// RuleConfig maps glob patterns to their own license file, style and comment preference
+type RuleConfig struct {
+	Paths      []string `mapstructure:"paths"`
+	License    string   `mapstructure:"license"`
+	LicenseID  string   `mapstructure:"license-id"`
+	Style      string   `mapstructure:"style"`
+	Comments   string   `mapstructure:"comments"`
+	HeaderMode string   `mapstructure:"header-mode"`
+}
+
+// Header modes
+const (
+	HeaderModeFull = "full" // Full license text
+	HeaderModeSPDX = "spdx" // SPDX short-form tags
+)
+
 // NewAppConfig returns default application config
 func NewAppConfig() AppConfig {
 	return AppConfig{
@@ -45,29 +103,285 @@ func (c *AppConfig) ToProcessorConfig() (*processor.Config, error) {
 	var licenseText string
 	var err error
 
-	// Load license file if provided
-	if c.LicenseFile != "" {
+	// Load license file or embedded license if provided
+	switch {
+	case c.LicenseFile != "" && c.LicenseID != "":
+		return nil, errors.NewValidationError("use either a license file or a license id, not both", "LicenseID")
+	case c.LicenseFile != "":
 		licenseText, err = c.loadLicenseFile()
 		if err != nil {
 			return nil, err
 		}
+	case c.LicenseID != "" && c.HeaderMode != HeaderModeSPDX:
+		licenseText, err = licenses.Get(c.LicenseID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if c.YearPolicy != "" {
+		if err := license.ValidateYearPolicy(c.YearPolicy); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := processor.ValidatePatchFormat(c.PatchFormat); err != nil {
+		return nil, err
+	}
+
+	if c.PatchFile != "" && c.OutputDir != "" {
+		return nil, errors.NewValidationError("use either --patch or --output-dir, not both", "OutputDir")
+	}
+	if c.CopyUnchanged && c.OutputDir == "" {
+		return nil, errors.NewValidationError("--copy-unchanged needs --output-dir", "CopyUnchanged")
+	}
+
+	if c.Since != "" && c.Staged {
+		return nil, errors.NewValidationError("use either --since or --staged, not both", "Since")
+	}
+
+	rules, err := c.loadRules(licenseText)
+	if err != nil {
+		return nil, err
+	}
+
+	encodings, err := c.loadEncodings()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := processor.ValidateGeneratedPolicy(c.GeneratedPolicy); err != nil {
+		return nil, err
+	}
+	generated, err := c.loadGeneratedPatterns()
+	if err != nil {
+		return nil, err
+	}
+
+	maxSize, err := parseSize(c.MaxSize)
+	if err != nil {
+		return nil, err
+	}
+	if c.MaxLines < 0 {
+		return nil, errors.NewValidationError("--max-lines must not be negative", "MaxLines")
+	}
+
+	switch c.HeaderMode {
+	case "", HeaderModeFull:
+	case HeaderModeSPDX:
+		if c.LicenseID == "" && licenseText == "" {
+			break // Only rules provide licenses
+		}
+		if licenseText, err = spdxLicense(c.LicenseID, licenseText); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errors.NewValidationError("header mode must be full or spdx", "HeaderMode")
 	}
 
 	// Convert to processor config
 	return &processor.Config{
-		LicenseText: licenseText,
-		Input:       c.Inputs,
-		Skip:        c.Skips,
-		Prompt:      c.Interactive,
+		LicenseText:       licenseText,
+		Input:             c.Inputs,
+		Skip:              c.Skips,
+		GitSince:          c.Since,
+		GitStaged:         c.Staged,
+		GitTrackedOnly:    c.TrackedOnly,
+		NoGitignore:       c.NoGitignore,
+		NoGitattributes:   c.NoGitattributes,
+		Encodings:         encodings,
+		GeneratedPolicy:   c.GeneratedPolicy,
+		GeneratedPatterns: generated,
+		MaxFileSize:       maxSize,
+		MaxLines:          c.MaxLines,
+		Prompt:            c.Interactive,
+		Jobs:              c.Jobs,
+		DryRun:            c.DryRun,
+		ShowDiff:          c.ShowDiff,
+		PatchFile:         c.PatchFile,
+		PatchFormat:       c.PatchFormat,
+		OutputDir:         c.OutputDir,
+		CopyUnchanged:     c.CopyUnchanged,
+		StateDir:          c.stateDir(),
 
 		PresetStyle:       c.HeaderStyle,
+		Holder:            c.Holder,
+		Year:              c.Year,
+		YearPolicy:        c.YearPolicy,
+		TemplateVars:      c.Vars,
 		ForceCommentStyle: c.ForceCommentStyle,
 		IgnoreFail:        c.IgnoreFail,
 		LogLevel:          c.LogLevel,
+		LogOutput:         c.LogOutput,
 		IsPreCommit:       c.IsPreCommit,
+		Rules:             rules,
+		RuleDir:           c.RuleDir,
 	}, nil
 }
 
+// stateDir returns the undo journal directory, "" when the journal is disabled
+func (c *AppConfig) stateDir() string {
+	if c.NoJournal {
+		return ""
+	}
+	return c.StateDir
+}
+
+// loadEncodings parses the pattern=encoding declarations
+func (c *AppConfig) loadEncodings() ([]processor.EncodingRule, error) {
+	var rules []processor.EncodingRule
+	for _, declared := range c.Encodings {
+		pattern, name, ok := strings.Cut(declared, "=")
+		if !ok || pattern == "" {
+			return nil, errors.NewValidationError(
+				fmt.Sprintf("encoding %q must be pattern=encoding", declared), "Encodings")
+		}
+		encoding, err := charset.Lookup(name)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, processor.EncodingRule{Pattern: pattern, Encoding: encoding})
+	}
+	return rules, nil
+}
+
+// loadGeneratedPatterns compiles the language=regexp generated-code markers
+func (c *AppConfig) loadGeneratedPatterns() ([]processor.GeneratedPattern, error) {
+	var patterns []processor.GeneratedPattern
+	for _, declared := range c.GeneratedPatterns {
+		lang, expr, ok := strings.Cut(declared, "=")
+		if !ok || lang == "" || expr == "" {
+			return nil, errors.NewValidationError(
+				fmt.Sprintf("generated pattern %q must be language=regexp", declared), "GeneratedPatterns")
+		}
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, errors.NewValidationError(
+				fmt.Sprintf("generated pattern %q: %v", declared, err), "GeneratedPatterns")
+		}
+		patterns = append(patterns, processor.GeneratedPattern{Language: strings.ToLower(lang), Pattern: re})
+	}
+	return patterns, nil
+}
+
+// sizeUnits are the suffixes parseSize accepts, longest first
+var sizeUnits = []struct {
+	suffix string
+	bytes  int64
+}{
+	{"gb", 1 << 30}, {"mb", 1 << 20}, {"kb", 1 << 10},
+	{"g", 1 << 30}, {"m", 1 << 20}, {"k", 1 << 10}, {"b", 1},
+}
+
+// parseSize parses a size such as "1MB", "512k" or "2048" in bytes. An empty
+// size or "0" means no limit.
+func parseSize(size string) (int64, error) {
+	s := strings.ToLower(strings.TrimSpace(size))
+	if s == "" {
+		return 0, nil
+	}
+	unit := int64(1)
+	for _, u := range sizeUnits {
+		if strings.HasSuffix(s, u.suffix) {
+			s, unit = strings.TrimSpace(strings.TrimSuffix(s, u.suffix)), u.bytes
+			break
+		}
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || n < 0 {
+		return 0, errors.NewValidationError(
+			fmt.Sprintf("invalid size %q, use bytes or a KB, MB or GB suffix", size), "MaxSize")
+	}
+	return n * unit, nil
+}
+
+// loadRules validates the configured rules and reads their license files.
+// defaultText is the top-level license text, used by SPDX rules without a license of their own.
+func (c *AppConfig) loadRules(defaultText string) ([]processor.Rule, error) {
+	var rules []processor.Rule
+	for i, rc := range c.Rules {
+		if len(rc.Paths) == 0 {
+			return nil, errors.NewValidationError(
+				fmt.Sprintf("rule %d has no paths", i+1), "Rules")
+		}
+
+		rule := processor.Rule{
+			Patterns:    rc.Paths,
+			PresetStyle: rc.Style,
+		}
+
+		switch force.ForceCommentStyle(rc.Comments) {
+		case "", force.No, force.Single, force.Multi:
+			rule.ForceCommentStyle = force.ForceCommentStyle(rc.Comments)
+		default:
+			return nil, errors.NewValidationError(
+				fmt.Sprintf("rule %d: comments must be one of no, single, or multi", i+1), "Rules")
+		}
+
+		mode := rc.HeaderMode
+		if mode == "" {
+			mode = c.HeaderMode
+		}
+		if mode != "" && mode != HeaderModeFull && mode != HeaderModeSPDX {
+			return nil, errors.NewValidationError(
+				fmt.Sprintf("rule %d: header-mode must be full or spdx", i+1), "Rules")
+		}
+
+		switch {
+		case rc.License != "" && rc.LicenseID != "":
+			return nil, errors.NewValidationError(
+				fmt.Sprintf("rule %d: use either license or license-id, not both", i+1), "Rules")
+		case rc.License != "":
+			content, err := os.ReadFile(rc.License)
+			if err != nil {
+				return nil, errors.NewValidationError(
+					fmt.Sprintf("rule %d: failed to read license file %s", i+1, rc.License), "Rules")
+			}
+			rule.LicenseText = string(content)
+		case rc.LicenseID != "" && mode != HeaderModeSPDX:
+			text, err := licenses.Get(rc.LicenseID)
+			if err != nil {
+				return nil, err
+			}
+			rule.LicenseText = text
+		}
+
+		if mode == HeaderModeSPDX {
+			id, text := rc.LicenseID, rule.LicenseText
+			if rc.License == "" && id == "" {
+				id, text = c.LicenseID, defaultText
+			}
+			spdxText, err := spdxLicense(id, text)
+			if err != nil {
+				return nil, errors.NewValidationError(fmt.Sprintf("rule %d: %v", i+1, err), "Rules")
+			}
+			rule.LicenseText = spdxText
+		}
+
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// spdxLicense returns the SPDX short-form template for a license id, or for the
+// SPDX-License-Identifier tag found in the license text when no id is given
+func spdxLicense(id, text string) (string, error) {
+	if id != "" {
+		if lic, ok := licenses.Lookup(id); ok {
+			id = lic.ID
+		}
+	} else {
+		id = license.FindSPDXIdentifier(text)
+	}
+
+	if id == "" {
+		return "", errors.NewValidationError(
+			"SPDX header mode needs --license-id or a license file with an SPDX-License-Identifier line",
+			"HeaderMode")
+	}
+	return license.SPDXTemplate(id), nil
+}
+
 func (c *AppConfig) loadLicenseFile() (string, error) {
 	if !filepath.IsAbs(c.LicenseFile) {
 		abs, err := filepath.Abs(c.LicenseFile)
@@ -0,0 +1,35 @@
+package config
+
+import "testing"
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		size    string
+		want    int64
+		wantErr bool
+	}{
+		{"", 0, false},
+		{"0", 0, false},
+		{"2048", 2048, false},
+		{"512KB", 512 << 10, false},
+		{"1MB", 1 << 20, false},
+		{"2m", 2 << 20, false},
+		{"1 GB", 1 << 30, false},
+		{"100b", 100, false},
+		{"1.5MB", 0, true},
+		{"-1", 0, true},
+		{"big", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.size, func(t *testing.T) {
+			got, err := parseSize(tt.size)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseSize(%q) error = %v, wantErr %v", tt.size, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseSize(%q) = %d, want %d", tt.size, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+// internal/config/file.go
+package config
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/jeeftor/license-manager/internal/errors"
+)
+
+// FileBaseName is the name (without extension) of the project configuration file
+const FileBaseName = ".license-manager"
+
+// FileExtensions lists the supported configuration formats in order of preference
+var FileExtensions = []string{".yaml", ".yml", ".toml"}
+
+// FindFile searches dir and each of its parents for a project configuration file.
+// An empty path is returned when no configuration file exists up to the filesystem root.
+func FindFile(dir string) (string, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", errors.NewFileError("invalid search directory", dir, "config")
+	}
+
+	for {
+		for _, ext := range FileExtensions {
+			candidate := filepath.Join(abs, FileBaseName+ext)
+			if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+				return candidate, nil
+			}
+		}
+
+		parent := filepath.Dir(abs)
+		if parent == abs {
+			return "", nil
+		}
+		abs = parent
+	}
+}
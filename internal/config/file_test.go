@@ -0,0 +1,41 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFindFile(t *testing.T) {
+	root := t.TempDir()
+	nested := filepath.Join(root, "a", "b", "c")
+	if err := os.MkdirAll(nested, 0755); err != nil {
+		t.Fatalf("Failed to create directories: %v", err)
+	}
+
+	// Config in the root is found from a nested directory
+	rootCfg := filepath.Join(root, ".license-manager.yaml")
+	if err := os.WriteFile(rootCfg, []byte("style: box\n"), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	found, err := FindFile(nested)
+	if err != nil {
+		t.Fatalf("FindFile() error: %v", err)
+	}
+	if found != rootCfg {
+		t.Errorf("FindFile() = %q, want %q", found, rootCfg)
+	}
+
+	// The closest config wins
+	closer := filepath.Join(root, "a", ".license-manager.toml")
+	if err := os.WriteFile(closer, []byte("style = \"box\"\n"), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	found, err = FindFile(nested)
+	if err != nil {
+		t.Fatalf("FindFile() error: %v", err)
+	}
+	if found != closer {
+		t.Errorf("FindFile() = %q, want %q", found, closer)
+	}
+}
@@ -0,0 +1,38 @@
+package diff
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+)
+
+// GitFile returns a git-style diff of a change to the file at path, which must
+// be slash separated and relative to where the patch is applied. The header
+// carries the blob ids and the file mode, so git apply and git am keep the mode.
+// It returns "" when a and b are equal.
+func GitFile(path string, mode os.FileMode, a, b string) string {
+	body := Unified("a/"+path, "b/"+path, a, b, DefaultContext)
+	if body == "" {
+		return ""
+	}
+	return fmt.Sprintf("diff --git a/%s b/%s\nindex %s..%s %s\n%s",
+		path, path, blobID(a), blobID(b), gitMode(mode), body)
+}
+
+// blobID returns the abbreviated git object id of a file with this content
+func blobID(content string) string {
+	h := sha1.New()
+	fmt.Fprintf(h, "blob %d\x00", len(content))
+	io.WriteString(h, content)
+	return hex.EncodeToString(h.Sum(nil))[:7]
+}
+
+// gitMode returns the git mode of a regular file
+func gitMode(mode os.FileMode) string {
+	if mode&0111 != 0 {
+		return "100755"
+	}
+	return "100644"
+}
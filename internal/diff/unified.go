@@ -0,0 +1,168 @@
+// Package diff renders line-based unified diffs
+package diff
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// DefaultContext is the number of unchanged lines shown around each change
+const DefaultContext = 3
+
+// line is a single line of the edit script
+type line struct {
+	kind byte // ' ', '-' or '+'
+	text string
+}
+
+// Unified returns a unified diff turning a into b, labelled with fromFile and
+// toFile, with the given number of context lines. It returns "" when a and b are equal.
+func Unified(fromFile, toFile, a, b string, context int) string {
+	if a == b {
+		return ""
+	}
+
+	lines := editScript(a, b)
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", fromFile, toFile)
+	for _, h := range hunks(lines, context) {
+		writeHunk(&sb, lines, h)
+	}
+	return sb.String()
+}
+
+// editScript computes the line-level edits between a and b
+func editScript(a, b string) []line {
+	// Encode every distinct line as one rune so the diff runs on whole lines
+	var table []string
+	index := map[string]rune{}
+	encode := func(text string) []rune {
+		var runes []rune
+		for _, l := range splitLines(text) {
+			r, ok := index[l]
+			if !ok {
+				r = lineRune(len(table))
+				index[l] = r
+				table = append(table, l)
+			}
+			runes = append(runes, r)
+		}
+		return runes
+	}
+	runesA, runesB := encode(a), encode(b)
+
+	dmp := diffmatchpatch.New()
+	var lines []line
+	for _, d := range dmp.DiffMainRunes(runesA, runesB, false) {
+		kind := byte(' ')
+		switch d.Type {
+		case diffmatchpatch.DiffDelete:
+			kind = '-'
+		case diffmatchpatch.DiffInsert:
+			kind = '+'
+		}
+		for _, r := range d.Text {
+			lines = append(lines, line{kind: kind, text: table[runeLine(r)]})
+		}
+	}
+	return lines
+}
+
+// surrogateGap is the number of UTF-16 surrogate code points, which are not valid runes
+const surrogateGap = 0xE000 - 0xD800
+
+// lineRune maps a line number to a valid rune
+func lineRune(i int) rune {
+	if i >= 0xD800 {
+		i += surrogateGap
+	}
+	return rune(i)
+}
+
+// runeLine is the inverse of lineRune
+func runeLine(r rune) int {
+	if r >= 0xE000 {
+		r -= surrogateGap
+	}
+	return int(r)
+}
+
+// splitLines splits text into lines that keep their trailing newline
+func splitLines(text string) []string {
+	parts := strings.SplitAfter(text, "\n")
+	if parts[len(parts)-1] == "" {
+		parts = parts[:len(parts)-1]
+	}
+	return parts
+}
+
+// hunk is a range [start, end) of the edit script
+type hunk struct {
+	start, end int
+}
+
+// hunks groups changed lines with their context, merging groups whose context overlaps
+func hunks(lines []line, context int) []hunk {
+	var result []hunk
+	for i, l := range lines {
+		if l.kind == ' ' {
+			continue
+		}
+		start := max(i-context, 0)
+		end := min(i+context+1, len(lines))
+		if n := len(result); n > 0 && start <= result[n-1].end {
+			result[n-1].end = end
+			continue
+		}
+		result = append(result, hunk{start: start, end: end})
+	}
+	return result
+}
+
+// writeHunk writes a hunk header and its lines
+func writeHunk(sb *strings.Builder, lines []line, h hunk) {
+	// Line numbers of the first hunk line in both files
+	oldStart, newStart := 1, 1
+	for _, l := range lines[:h.start] {
+		if l.kind != '+' {
+			oldStart++
+		}
+		if l.kind != '-' {
+			newStart++
+		}
+	}
+
+	oldCount, newCount := 0, 0
+	for _, l := range lines[h.start:h.end] {
+		if l.kind != '+' {
+			oldCount++
+		}
+		if l.kind != '-' {
+			newCount++
+		}
+	}
+
+	fmt.Fprintf(sb, "@@ -%s +%s @@\n", hunkRange(oldStart, oldCount), hunkRange(newStart, newCount))
+	for _, l := range lines[h.start:h.end] {
+		sb.WriteByte(l.kind)
+		sb.WriteString(l.text)
+		if !strings.HasSuffix(l.text, "\n") {
+			sb.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
+
+// hunkRange formats a hunk range the way diff -u does
+func hunkRange(start, count int) string {
+	switch count {
+	case 0:
+		return fmt.Sprintf("%d,0", start-1)
+	case 1:
+		return fmt.Sprintf("%d", start)
+	default:
+		return fmt.Sprintf("%d,%d", start, count)
+	}
+}
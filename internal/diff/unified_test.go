@@ -0,0 +1,68 @@
+package diff
+
+import "testing"
+
+func TestUnified(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want string
+	}{
+		{
+			name: "equal",
+			a:    "same\n",
+			b:    "same\n",
+			want: "",
+		},
+		{
+			name: "changed line with context",
+			a:    "1\n2\n3\n4\n5\n6\n7\n8\n",
+			b:    "1\n2\n3\n4\nfive\n6\n7\n8\n",
+			want: "--- a\n+++ b\n@@ -2,7 +2,7 @@\n 2\n 3\n 4\n-5\n+five\n 6\n 7\n 8\n",
+		},
+		{
+			name: "insert at start",
+			a:    "x\ny\n",
+			b:    "header\nx\ny\n",
+			want: "--- a\n+++ b\n@@ -1,2 +1,3 @@\n+header\n x\n y\n",
+		},
+		{
+			name: "new file",
+			a:    "",
+			b:    "x\n",
+			want: "--- a\n+++ b\n@@ -0,0 +1 @@\n+x\n",
+		},
+		{
+			name: "missing newline at end",
+			a:    "x\ny\n",
+			b:    "x\ny",
+			want: "--- a\n+++ b\n@@ -1,2 +1,2 @@\n x\n-y\n+y\n\\ No newline at end of file\n",
+		},
+		{
+			name: "separate hunks",
+			a:    "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n",
+			b:    "one\n2\n3\n4\n5\n6\n7\n8\n9\nten\n",
+			want: "--- a\n+++ b\n@@ -1,4 +1,4 @@\n-1\n+one\n 2\n 3\n 4\n@@ -7,4 +7,4 @@\n 7\n 8\n 9\n-10\n+ten\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Unified("a", "b", tt.a, tt.b, DefaultContext); got != tt.want {
+				t.Errorf("Unified() =\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGitFile(t *testing.T) {
+	want := "diff --git a/run.sh b/run.sh\n" +
+		"index 8b2fe54..5cafc1f 100755\n" +
+		"--- a/run.sh\n+++ b/run.sh\n@@ -1 +1,2 @@\n+# header\n echo hi\n"
+	if got := GitFile("run.sh", 0755, "echo hi\n", "# header\necho hi\n"); got != want {
+		t.Errorf("GitFile() = %q, want %q", got, want)
+	}
+	if got := GitFile("run.sh", 0644, "same\n", "same\n"); got != "" {
+		t.Errorf("GitFile() = %q for equal content", got)
+	}
+}
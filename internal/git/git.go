@@ -0,0 +1,209 @@
+// Package git lists files known to the local git binary
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/jeeftor/license-manager/internal/errors"
+)
+
+// Repo is a git working tree
+type Repo struct {
+	Root string // Absolute path of the top-level directory
+
+	yearsOnce sync.Once
+	years     map[string]commitYears // keyed by slash separated path relative to Root
+	yearsErr  error
+}
+
+// Open finds the repository containing dir
+func Open(dir string) (*Repo, error) {
+	out, err := run(dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, errors.NewFileError(err.Error(), dir, "git")
+	}
+	return &Repo{Root: filepath.FromSlash(strings.TrimSpace(out))}, nil
+}
+
+// CommonDir returns the absolute path of the git directory of the repository
+// containing dir, shared by all its worktrees
+func CommonDir(dir string) (string, error) {
+	out, err := run(dir, "rev-parse", "--git-common-dir")
+	if err != nil {
+		return "", errors.NewFileError(err.Error(), dir, "git")
+	}
+	common := filepath.FromSlash(strings.TrimSpace(out))
+	if !filepath.IsAbs(common) {
+		common = filepath.Join(dir, common)
+	}
+	return filepath.Abs(common)
+}
+
+// run executes git in dir and returns its stdout
+func run(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("git %s: %s", args[0], msg)
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return stdout.String(), nil
+}
+
+// files runs a git command that prints NUL separated paths relative to the root
+// and returns them as absolute paths
+func (r *Repo) files(args ...string) ([]string, error) {
+	out, err := run(r.Root, args...)
+	if err != nil {
+		return nil, errors.NewFileError(err.Error(), r.Root, "git")
+	}
+
+	var files []string
+	for _, name := range strings.Split(out, "\x00") {
+		if name != "" {
+			files = append(files, filepath.Join(r.Root, filepath.FromSlash(name)))
+		}
+	}
+	return files, nil
+}
+
+// Tracked returns the files in the index
+func (r *Repo) Tracked() ([]string, error) {
+	return r.files("ls-files", "-z")
+}
+
+// Staged returns the files added, copied, modified or renamed in the index
+func (r *Repo) Staged() ([]string, error) {
+	return r.files("diff", "--cached", "--name-only", "-z", "--diff-filter=ACMR")
+}
+
+// ChangedSince returns the files that differ between the merge base of ref and
+// HEAD and the working tree, plus untracked files that are not ignored
+func (r *Repo) ChangedSince(ref string) ([]string, error) {
+	out, err := run(r.Root, "merge-base", ref, "HEAD")
+	if err != nil {
+		return nil, errors.NewFileError(err.Error(), ref, "git")
+	}
+	base := strings.TrimSpace(out)
+
+	changed, err := r.files("diff", "--name-only", "-z", "--diff-filter=ACMR", base)
+	if err != nil {
+		return nil, err
+	}
+	untracked, err := r.files("ls-files", "-z", "--others", "--exclude-standard")
+	if err != nil {
+		return nil, err
+	}
+	return append(changed, untracked...), nil
+}
+
+// commitYears holds the years of the first and last commit of a file
+type commitYears struct {
+	First, Last int
+}
+
+// CommitYears returns the years of the first and last commit of a file, following
+// renames. Both are 0 when the file has no commits. The history of the whole
+// repository is read with a single git log on first use.
+func (r *Repo) CommitYears(path string) (first, last int, err error) {
+	r.yearsOnce.Do(func() { r.years, r.yearsErr = r.allCommitYears() })
+	if r.yearsErr != nil {
+		return 0, 0, r.yearsErr
+	}
+
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return 0, 0, errors.NewFileError(err.Error(), path, "git")
+	}
+	if real, err := filepath.EvalSymlinks(abs); err == nil {
+		abs = real
+	}
+	rel, err := filepath.Rel(r.Root, abs)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return 0, 0, errors.NewFileError("file is outside the repository", path, "git")
+	}
+	years := r.years[filepath.ToSlash(rel)]
+	return years.First, years.Last, nil
+}
+
+// allCommitYears walks the log from the newest commit back, charging each commit
+// to the name its files have in HEAD
+func (r *Repo) allCommitYears() (map[string]commitYears, error) {
+	out, err := run(r.Root, "log", "-M", "--name-status", "-z", "--format=%x01%ad", "--date=format:%Y")
+	if err != nil {
+		if _, headErr := run(r.Root, "rev-parse", "--verify", "-q", "HEAD"); headErr != nil {
+			return map[string]commitYears{}, nil // no commits yet
+		}
+		return nil, errors.NewFileError(err.Error(), r.Root, "git")
+	}
+
+	years := make(map[string]commitYears)
+	renamed := make(map[string]string) // old name -> name in HEAD
+	current := func(name string) string {
+		if to, ok := renamed[name]; ok {
+			return to
+		}
+		return name
+	}
+	record := func(name string, year int) {
+		y := years[name]
+		// Commit dates need not be in order, e.g. after a rebase
+		if y.First == 0 || year < y.First {
+			y.First = year
+		}
+		y.Last = max(y.Last, year)
+		years[name] = y
+	}
+
+	year := 0
+	fields := strings.Split(out, "\x00")
+	for i := 0; i < len(fields); i++ {
+		field := strings.TrimPrefix(fields[i], "\n")
+		switch {
+		case field == "":
+		case strings.HasPrefix(field, "\x01"):
+			year, err = strconv.Atoi(field[1:])
+			if err != nil {
+				return nil, errors.NewFileError("unexpected git log output: "+field, r.Root, "git")
+			}
+		case i+1 < len(fields) && (field[0] == 'R' || field[0] == 'C'):
+			if i+2 >= len(fields) {
+				return nil, errors.NewFileError("unexpected git log output: "+field, r.Root, "git")
+			}
+			from, to := fields[i+1], current(fields[i+2])
+			i += 2
+			record(to, year)
+			if field[0] == 'R' {
+				renamed[from] = to
+			}
+		case i+1 < len(fields):
+			i++
+			record(current(fields[i]), year)
+		}
+	}
+	return years, nil
+}
+
+// Ident returns the "Name <email>" git commits as in dir, or "" when git has no
+// identity configured
+func Ident(dir string) string {
+	out, err := run(dir, "var", "GIT_AUTHOR_IDENT")
+	if err != nil {
+		return ""
+	}
+	if i := strings.LastIndex(out, ">"); i >= 0 {
+		return out[:i+1]
+	}
+	return ""
+}
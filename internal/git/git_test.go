@@ -0,0 +1,191 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+// initRepo creates a repository with a commit on main and returns its root
+func initRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	dir := t.TempDir()
+	gitRun(t, dir, "init", "-q", "-b", "main")
+	gitRun(t, dir, "config", "user.email", "test@example.com")
+	gitRun(t, dir, "config", "user.name", "Test")
+	writeFile(t, dir, "old.go", "package main\n")
+	writeFile(t, dir, "lib/keep.go", "package lib\n")
+	writeFile(t, dir, ".gitignore", "*.log\n")
+	gitRun(t, dir, "add", ".")
+	gitRun(t, dir, "commit", "-q", "-m", "initial")
+	return dir
+}
+
+func gitRun(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %v failed: %v\n%s", args, err, out)
+	}
+}
+
+func writeFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	path := filepath.Join(dir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// names returns the paths relative to root, sorted
+func names(t *testing.T, root string, files []string) []string {
+	t.Helper()
+	var rel []string
+	for _, file := range files {
+		r, err := filepath.Rel(root, file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rel = append(rel, filepath.ToSlash(r))
+	}
+	sort.Strings(rel)
+	return rel
+}
+
+func TestRepoSelections(t *testing.T) {
+	dir := initRepo(t)
+
+	gitRun(t, dir, "checkout", "-q", "-b", "feature")
+	writeFile(t, dir, "committed.go", "package main\n")
+	gitRun(t, dir, "add", "committed.go")
+	gitRun(t, dir, "commit", "-q", "-m", "feature")
+	writeFile(t, dir, "old.go", "package main\n\nfunc main() {}\n")
+	writeFile(t, dir, "staged.go", "package main\n")
+	gitRun(t, dir, "add", "staged.go")
+	writeFile(t, dir, "untracked.go", "package main\n")
+	writeFile(t, dir, "debug.log", "ignored\n")
+
+	repo, err := Open(filepath.Join(dir, "lib"))
+	if err != nil {
+		t.Fatalf("Open() failed: %v", err)
+	}
+	root, _ := filepath.EvalSymlinks(dir)
+	if got, _ := filepath.EvalSymlinks(repo.Root); got != root {
+		t.Fatalf("Root = %q, want %q", repo.Root, dir)
+	}
+
+	tests := []struct {
+		name string
+		list func() ([]string, error)
+		want []string
+	}{
+		{"tracked", repo.Tracked, []string{".gitignore", "committed.go", "lib/keep.go", "old.go", "staged.go"}},
+		{"staged", repo.Staged, []string{"staged.go"}},
+		{"since", func() ([]string, error) { return repo.ChangedSince("main") }, []string{"committed.go", "old.go", "staged.go", "untracked.go"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files, err := tt.list()
+			if err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+			got := names(t, repo.Root, files)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("got %v, want %v", got, tt.want)
+					break
+				}
+			}
+		})
+	}
+
+	if _, err := repo.ChangedSince("no-such-ref"); err == nil {
+		t.Error("ChangedSince() with an unknown ref should fail")
+	}
+}
+
+func TestOpenOutsideRepo(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir := t.TempDir()
+	t.Setenv("GIT_CEILING_DIRECTORIES", filepath.Dir(dir))
+	if _, err := Open(dir); err == nil {
+		t.Error("Open() outside a repository should fail")
+	}
+}
+
+func TestCommonDir(t *testing.T) {
+	dir := initRepo(t)
+	writeFile(t, dir, "sub/file.go", "package sub\n")
+
+	got, err := CommonDir(filepath.Join(dir, "sub"))
+	if err != nil {
+		t.Fatalf("CommonDir() failed: %v", err)
+	}
+	want, _ := filepath.EvalSymlinks(filepath.Join(dir, ".git"))
+	if real, _ := filepath.EvalSymlinks(got); real != want {
+		t.Errorf("CommonDir() = %s, want %s", got, want)
+	}
+}
+
+func TestCommitYears(t *testing.T) {
+	dir := initRepo(t)
+	commitAt := func(date, message string) {
+		cmd := exec.Command("git", "commit", "-q", "-a", "-m", message)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_DATE="+date, "GIT_COMMITTER_DATE="+date)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git commit failed: %v\n%s", err, out)
+		}
+	}
+
+	body := "package history\n\n// Started in 2019, long enough to be told apart from the other files\nfunc History() {}\n"
+	writeFile(t, dir, "history.go", body)
+	gitRun(t, dir, "add", "history.go")
+	commitAt("2019-03-01T12:00:00", "history")
+	gitRun(t, dir, "mv", "history.go", "renamed.go")
+	writeFile(t, dir, "renamed.go", body+"\n// Renamed in 2023\n")
+	gitRun(t, dir, "add", "renamed.go")
+	commitAt("2023-06-01T12:00:00", "rename")
+	writeFile(t, dir, "history.go", "package history\n\n// A new file under the old name\n")
+	gitRun(t, dir, "add", "history.go")
+	commitAt("2024-02-01T12:00:00", "reuse")
+	writeFile(t, dir, "new.go", "package main\n")
+
+	repo, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open() failed: %v", err)
+	}
+
+	tests := []struct {
+		file        string
+		first, last int
+	}{
+		{"renamed.go", 2019, 2023}, // history follows the rename
+		{"history.go", 2024, 2024}, // the old name starts over
+		{"new.go", 0, 0},
+	}
+	for _, tt := range tests {
+		first, last, err := repo.CommitYears(filepath.Join(dir, tt.file))
+		if err != nil {
+			t.Fatalf("CommitYears(%s) failed: %v", tt.file, err)
+		}
+		if first != tt.first || last != tt.last {
+			t.Errorf("CommitYears(%s) = %d, %d, want %d, %d", tt.file, first, last, tt.first, tt.last)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package ignore
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// GitAttributesFile is read in every directory
+const GitAttributesFile = ".gitattributes"
+
+// Kinds of files that .gitattributes excludes from licensing
+const (
+	Generated = "generated" // linguist-generated
+	Vendored  = "vendored"  // linguist-vendored
+	Binary    = "binary"    // binary, or -text
+)
+
+// attributeRule is a single line of a .gitattributes file
+type attributeRule struct {
+	pattern pattern
+	values  map[string]string // "" unspecifies an attribute
+}
+
+// macros expand to the attributes they set, as built into git
+var macros = map[string]map[string]string{
+	"binary": {"binary": "true", "diff": "false", "merge": "false", "text": "false"},
+}
+
+// parseAttributeLine parses a .gitattributes line, ok is false for blank lines,
+// comments, macro definitions and negative patterns, which git does not allow
+func parseAttributeLine(line string) (rule attributeRule, ok bool) {
+	fields := strings.Fields(line)
+	if len(fields) < 2 || strings.HasPrefix(fields[0], "#") || strings.HasPrefix(fields[0], "[attr]") {
+		return rule, false
+	}
+
+	p, ok := parsePattern(fields[0])
+	if !ok || p.negate {
+		return rule, false
+	}
+
+	rule = attributeRule{pattern: p, values: make(map[string]string)}
+	for _, field := range fields[1:] {
+		switch {
+		case strings.HasPrefix(field, "-"):
+			rule.values[field[1:]] = "false"
+		case strings.HasPrefix(field, "!"):
+			rule.values[field[1:]] = ""
+		case strings.Contains(field, "="):
+			name, value, _ := strings.Cut(field, "=")
+			rule.values[name] = value
+		default:
+			rule.values[field] = "true"
+			for name, value := range macros[field] {
+				rule.values[name] = value
+			}
+		}
+	}
+	return rule, true
+}
+
+// Attributes reads .gitattributes files of a path's directory and its parents,
+// up to the root of the git repository or, outside a repository, up to the root
+// directory given to NewAttributes. Deeper files take precedence, the last
+// matching line wins, and .git/info/attributes overrides everything. It is safe
+// for concurrent use.
+type Attributes struct {
+	mu    sync.Mutex
+	tree  tree
+	rules map[string][]attributeRule
+}
+
+// NewAttributes creates an empty Attributes cache that reads no .gitattributes
+// above root outside a git repository
+func NewAttributes(root string) *Attributes {
+	return &Attributes{tree: newTree(root), rules: make(map[string][]attributeRule)}
+}
+
+// Lookup returns the attributes specified for a file. Unset attributes ("-name") are "false".
+func (a *Attributes) Lookup(path string) map[string]string {
+	values := make(map[string]string)
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return values
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	chain := a.tree.chain(filepath.Dir(abs))
+	apply := func(dir string, rules []attributeRule) {
+		rel, err := filepath.Rel(dir, abs)
+		if err != nil {
+			return
+		}
+		rel = filepath.ToSlash(rel)
+		for _, rule := range rules {
+			if !rule.pattern.matches(rel, false) {
+				continue
+			}
+			for name, value := range rule.values {
+				if value == "" {
+					delete(values, name)
+				} else {
+					values[name] = value
+				}
+			}
+		}
+	}
+
+	for _, dir := range chain {
+		apply(dir, a.rulesFor(filepath.Join(dir, GitAttributesFile)))
+	}
+	if top := chain[0]; a.tree.isTop(top) {
+		apply(top, a.rulesFor(filepath.Join(top, ".git", "info", "attributes")))
+	}
+	return values
+}
+
+// Exclusion returns Generated, Vendored or Binary when the attributes of a file
+// exclude it from licensing, or "" otherwise
+func (a *Attributes) Exclusion(path string) string {
+	values := a.Lookup(path)
+	isSet := func(name string) bool {
+		value, ok := values[name]
+		return ok && value != "false"
+	}
+
+	switch {
+	case isSet("linguist-generated"):
+		return Generated
+	case isSet("linguist-vendored"):
+		return Vendored
+	case isSet("binary"), values["text"] == "false":
+		return Binary
+	}
+	return ""
+}
+
+// rulesFor reads and caches an attributes file, a missing file has no rules
+func (a *Attributes) rulesFor(file string) []attributeRule {
+	if rules, ok := a.rules[file]; ok {
+		return rules
+	}
+
+	var rules []attributeRule
+	if f, err := os.Open(file); err == nil {
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			if rule, ok := parseAttributeLine(scanner.Text()); ok {
+				rules = append(rules, rule)
+			}
+		}
+		f.Close()
+	}
+	a.rules[file] = rules
+	return rules
+}
@@ -0,0 +1,79 @@
+package ignore
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseAttributeLine(t *testing.T) {
+	tests := []struct {
+		line   string
+		wantOK bool
+		want   map[string]string
+	}{
+		{"", false, nil},
+		{"# comment", false, nil},
+		{"*.go", false, nil},
+		{"[attr]generated linguist-generated", false, nil},
+		{"!*.go text", false, nil},
+		{"*.pb.go linguist-generated", true, map[string]string{"linguist-generated": "true"}},
+		{"vendor/** linguist-vendored=true -diff", true, map[string]string{"linguist-vendored": "true", "diff": "false"}},
+		{"docs/** !linguist-documentation", true, map[string]string{"linguist-documentation": ""}},
+		{"*.png binary", true, map[string]string{"binary": "true", "diff": "false", "merge": "false", "text": "false"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			got, ok := parseAttributeLine(tt.line)
+			if ok != tt.wantOK {
+				t.Fatalf("parseAttributeLine(%q) ok = %v, want %v", tt.line, ok, tt.wantOK)
+			}
+			if len(got.values) != len(tt.want) {
+				t.Fatalf("parseAttributeLine(%q) = %v, want %v", tt.line, got.values, tt.want)
+			}
+			for name, value := range tt.want {
+				if got.values[name] != value {
+					t.Errorf("parseAttributeLine(%q)[%s] = %q, want %q", tt.line, name, got.values[name], value)
+				}
+			}
+		})
+	}
+}
+
+func TestAttributesExclusion(t *testing.T) {
+	root := t.TempDir()
+	if err := os.Mkdir(filepath.Join(root, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, root, GitAttributesFile, `
+*.pb.go linguist-generated=true
+vendor/** linguist-vendored
+third_party/** linguist-vendored
+*.dat -text
+*.bin binary
+`)
+	writeFile(t, root, "vendor/"+GitAttributesFile, "ours/** -linguist-vendored\n")
+	writeFile(t, root, ".git/info/attributes", "third_party/patched.go !linguist-vendored\n")
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"main.go", ""},
+		{"api/service.pb.go", Generated},
+		{"vendor/github.com/x/x.go", Vendored},
+		{"vendor/ours/x.go", ""},
+		{"third_party/lib.go", Vendored},
+		{"third_party/patched.go", ""},
+		{"testdata/fixture.dat", Binary},
+		{"firmware.bin", Binary},
+	}
+
+	attrs := NewAttributes(root)
+	for _, tt := range tests {
+		if got := attrs.Exclusion(filepath.Join(root, filepath.FromSlash(tt.path))); got != tt.want {
+			t.Errorf("Exclusion(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
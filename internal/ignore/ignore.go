@@ -0,0 +1,180 @@
+// Package ignore matches paths against .gitignore, .licenseignore and .gitattributes files
+package ignore
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v2"
+)
+
+// File names read in every directory
+const (
+	GitIgnoreFile     = ".gitignore"
+	LicenseIgnoreFile = ".licenseignore" // Same syntax, only excludes files from licensing
+)
+
+// pattern is a single line of an ignore file
+type pattern struct {
+	glob    string // doublestar pattern relative to the ignore file's directory
+	negate  bool   // "!" re-includes a previously excluded path
+	dirOnly bool   // trailing "/" only matches directories
+}
+
+// parsePattern parses an ignore file line, ok is false for blank lines and comments
+func parsePattern(line string) (p pattern, ok bool) {
+	line = strings.TrimSuffix(line, "\r")
+	for strings.HasSuffix(line, " ") && !strings.HasSuffix(line, `\ `) {
+		line = line[:len(line)-1]
+	}
+	if line == "" || strings.HasPrefix(line, "#") {
+		return p, false
+	}
+
+	switch {
+	case strings.HasPrefix(line, "!"):
+		p.negate = true
+		line = line[1:]
+	case strings.HasPrefix(line, `\!`), strings.HasPrefix(line, `\#`):
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		p.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if line == "" {
+		return p, false
+	}
+
+	// A slash anywhere but the end anchors the pattern to the ignore file's directory
+	if strings.Contains(line, "/") {
+		p.glob = strings.TrimPrefix(line, "/")
+	} else {
+		p.glob = "**/" + line
+	}
+	return p, true
+}
+
+// matches reports whether a slash separated path relative to the ignore file matches
+func (p pattern) matches(rel string, isDir bool) bool {
+	if p.dirOnly && !isDir {
+		return false
+	}
+	matched, err := doublestar.Match(p.glob, rel)
+	return err == nil && matched
+}
+
+// Matcher decides whether paths are excluded by the ignore files of their
+// directory and its parents, up to the root of the git repository or, outside
+// a repository, up to the root directory given to New. Patterns
+// of deeper directories take precedence, and the last matching pattern wins.
+// A Matcher caches what it reads and is not safe for concurrent use.
+type Matcher struct {
+	tree
+	names      []string // ignore files read in every directory, in order of precedence
+	gitignore  bool
+	rules      map[string][]pattern
+	ignoredDir map[string]bool
+}
+
+// New creates a Matcher for .licenseignore files, and for .gitignore files and
+// .git/info/exclude when gitignore is true. Outside a git repository no ignore
+// files above root are read.
+func New(gitignore bool, root string) *Matcher {
+	names := []string{LicenseIgnoreFile}
+	if gitignore {
+		names = []string{GitIgnoreFile, LicenseIgnoreFile}
+	}
+	return &Matcher{
+		names:      names,
+		gitignore:  gitignore,
+		tree:       newTree(root),
+		rules:      make(map[string][]pattern),
+		ignoredDir: make(map[string]bool),
+	}
+}
+
+// Ignored reports whether path, or one of the directories containing it, is excluded
+func (m *Matcher) Ignored(path string, isDir bool) bool {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	if filepath.Base(abs) == ".git" {
+		return true
+	}
+
+	// A file inside an excluded directory can not be re-included
+	chain := m.chain(filepath.Dir(abs))
+	for i := 1; i < len(chain); i++ {
+		ignored, ok := m.ignoredDir[chain[i]]
+		if !ok {
+			ignored = filepath.Base(chain[i]) == ".git" || m.match(chain[:i], chain[i], true)
+			m.ignoredDir[chain[i]] = ignored
+		}
+		if ignored {
+			return true
+		}
+	}
+	return m.match(chain, abs, isDir)
+}
+
+// match applies the rules of every directory in chain to path
+func (m *Matcher) match(chain []string, path string, isDir bool) bool {
+	ignored := false
+	for _, dir := range chain {
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			continue
+		}
+		rel = filepath.ToSlash(rel)
+		for _, p := range m.rulesFor(dir) {
+			if p.matches(rel, isDir) {
+				ignored = !p.negate
+			}
+		}
+	}
+	return ignored
+}
+
+// rulesFor reads the ignore files of a directory
+func (m *Matcher) rulesFor(dir string) []pattern {
+	if rules, ok := m.rules[dir]; ok {
+		return rules
+	}
+
+	var files []string
+	if m.gitignore && m.isTop(dir) {
+		files = append(files, filepath.Join(dir, ".git", "info", "exclude"))
+	}
+	for _, name := range m.names {
+		files = append(files, filepath.Join(dir, name))
+	}
+
+	var rules []pattern
+	for _, file := range files {
+		rules = append(rules, readPatterns(file)...)
+	}
+	m.rules[dir] = rules
+	return rules
+}
+
+// readPatterns reads an ignore file, a missing file has no patterns
+func readPatterns(file string) []pattern {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var patterns []pattern
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if p, ok := parsePattern(scanner.Text()); ok {
+			patterns = append(patterns, p)
+		}
+	}
+	return patterns
+}
@@ -0,0 +1,129 @@
+package ignore
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParsePattern(t *testing.T) {
+	tests := []struct {
+		line   string
+		wantOK bool
+		want   pattern
+	}{
+		{"", false, pattern{}},
+		{"# comment", false, pattern{}},
+		{"*.log", true, pattern{glob: "**/*.log"}},
+		{"build/", true, pattern{glob: "**/build", dirOnly: true}},
+		{"/dist", true, pattern{glob: "dist"}},
+		{"docs/*.md", true, pattern{glob: "docs/*.md"}},
+		{"!keep.log", true, pattern{glob: "**/keep.log", negate: true}},
+		{`\!important`, true, pattern{glob: "**/!important"}},
+		{`\#hash`, true, pattern{glob: "**/#hash"}},
+		{"trailing   ", true, pattern{glob: "**/trailing"}},
+		{"a/**/b\r", true, pattern{glob: "a/**/b"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			got, ok := parsePattern(tt.line)
+			if ok != tt.wantOK {
+				t.Fatalf("parsePattern(%q) ok = %v, want %v", tt.line, ok, tt.wantOK)
+			}
+			if ok && got != tt.want {
+				t.Errorf("parsePattern(%q) = %+v, want %+v", tt.line, got, tt.want)
+			}
+		})
+	}
+}
+
+func writeFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	path := filepath.Join(dir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMatcherIgnored(t *testing.T) {
+	root := t.TempDir()
+	if err := os.Mkdir(filepath.Join(root, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, root, ".git/info/exclude", "*.tmp\n")
+	writeFile(t, root, GitIgnoreFile, "*.log\n!keep.log\nbuild/\n/dist\nnode_modules\n")
+	writeFile(t, root, LicenseIgnoreFile, "third_party/\n*.pb.go\n")
+	writeFile(t, root, "pkg/"+GitIgnoreFile, "!debug.log\nlocal.go\n")
+	writeFile(t, root, "pkg/sub/"+LicenseIgnoreFile, "!api.pb.go\n")
+
+	tests := []struct {
+		path      string
+		isDir     bool
+		want      bool
+		gitignore bool
+	}{
+		{"main.go", false, false, true},
+		{"app.log", false, true, true},
+		{"keep.log", false, false, true},
+		{"scratch.tmp", false, true, true},
+		{"build", true, true, true},
+		{"build", false, false, true}, // dir-only pattern
+		{"build/out.go", false, true, true},
+		{"src/build/out.go", false, true, true},
+		{"dist/app.js", false, true, true},
+		{"src/dist/app.js", false, false, true}, // anchored pattern
+		{"web/node_modules/x/index.js", false, true, true},
+		{"pkg/debug.log", false, false, true}, // deeper negation wins
+		{"pkg/other.log", false, true, true},
+		{"pkg/local.go", false, true, true},
+		{"local.go", false, false, true},
+		{"third_party/lib.go", false, true, true},
+		{"api.pb.go", false, true, true},
+		{"pkg/sub/api.pb.go", false, false, true},
+		{"build/!keep/keep.log", false, true, true}, // excluded directory can not be re-included
+		{".git/config", false, true, true},
+
+		// Without gitignore only .licenseignore applies
+		{"app.log", false, false, false},
+		{"scratch.tmp", false, false, false},
+		{"third_party/lib.go", false, true, false},
+	}
+
+	matchers := map[bool]*Matcher{true: New(true, root), false: New(false, root)}
+	for _, tt := range tests {
+		got := matchers[tt.gitignore].Ignored(filepath.Join(root, filepath.FromSlash(tt.path)), tt.isDir)
+		if got != tt.want {
+			t.Errorf("Ignored(%q, dir=%v, gitignore=%v) = %v, want %v", tt.path, tt.isDir, tt.gitignore, got, tt.want)
+		}
+	}
+}
+
+func TestMatcherOutsideRepository(t *testing.T) {
+	parent := t.TempDir()
+	root := filepath.Join(parent, "project")
+	writeFile(t, parent, LicenseIgnoreFile, "*.go\n")
+	writeFile(t, root, LicenseIgnoreFile, "*.gen.go\n")
+	writeFile(t, parent, "other/"+LicenseIgnoreFile, "*.py\n")
+
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"project/main.go", false}, // ignore files above the root are not read
+		{"project/api.gen.go", true},
+		{"project/pkg/api.gen.go", true},
+		{"other/main.go", true}, // paths outside the root see every parent
+		{"other/main.py", true},
+	}
+
+	m := New(false, root)
+	for _, tt := range tests {
+		if got := m.Ignored(filepath.Join(parent, filepath.FromSlash(tt.path)), false); got != tt.want {
+			t.Errorf("Ignored(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package ignore
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// tree caches which directories are repository roots
+type tree struct {
+	root string // Where the search for ignore files stops outside a repository
+	tops map[string]bool
+}
+
+func newTree(root string) tree {
+	if root != "" {
+		if abs, err := filepath.Abs(root); err == nil {
+			root = abs
+		}
+	}
+	return tree{root: root, tops: make(map[string]bool)}
+}
+
+// chain returns dir and its parents up to the repository root, outermost first.
+// Outside a repository the chain ends at the tree's root directory when dir is
+// below it, and includes every parent directory otherwise.
+func (t tree) chain(dir string) []string {
+	var dirs []string
+	for {
+		dirs = append(dirs, dir)
+		if t.isTop(dir) {
+			break
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			dirs = t.limit(dirs)
+			break
+		}
+		dir = parent
+	}
+	for i, j := 0, len(dirs)-1; i < j; i, j = i+1, j-1 {
+		dirs[i], dirs[j] = dirs[j], dirs[i]
+	}
+	return dirs
+}
+
+// limit cuts a chain that reached the filesystem root at the tree's root directory
+func (t tree) limit(dirs []string) []string {
+	if t.root == "" {
+		return dirs
+	}
+	for i, dir := range dirs {
+		if dir == t.root {
+			return dirs[:i+1]
+		}
+	}
+	return dirs
+}
+
+// isTop reports whether dir is the root of a git repository
+func (t tree) isTop(dir string) bool {
+	top, ok := t.tops[dir]
+	if !ok {
+		_, err := os.Stat(filepath.Join(dir, ".git"))
+		top = err == nil
+		t.tops[dir] = top
+	}
+	return top
+}
@@ -0,0 +1,280 @@
+// Package journal records the files a run changed, so the run can be undone
+package journal
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jeeftor/license-manager/internal/errors"
+)
+
+// File names inside a run directory
+const (
+	journalFile = "journal.json" // Run header, written once
+	entriesFile = "files.jsonl"  // One Entry per line, appended by Record
+	runsDir     = "runs"         // Below the state directory
+)
+
+// KeepRuns is the number of runs kept in the state directory
+const KeepRuns = 10
+
+// Entry is a file changed by a run
+type Entry struct {
+	Path   string `json:"path"`          // Absolute path of the file
+	Backup string `json:"backup"`        // Name of the copy of the original inside the run directory
+	Before string `json:"before_sha256"` // Hash of the original content
+	After  string `json:"after_sha256"`  // Hash of the content the run wrote
+}
+
+// Journal lists the files changed by one run. It is safe for concurrent use.
+type Journal struct {
+	ID      string    `json:"id"`
+	Command string    `json:"command"`
+	Created time.Time `json:"created"`
+	Files   []Entry   `json:"-"`
+
+	dir     string // Run directory, created by the first Record
+	backups int    // Number of backups taken, names the next one
+	mu      sync.Mutex
+}
+
+// New returns an empty journal for a run of command. Nothing is written to
+// stateDir until the first file is recorded.
+func New(stateDir, command string) *Journal {
+	return &Journal{
+		Command: command,
+		Created: time.Now().UTC(),
+		dir:     filepath.Join(stateDir, runsDir),
+	}
+}
+
+// Hash returns the hex encoded SHA-256 of content
+func Hash(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// Record saves a copy of the current content of path, then calls write to
+// replace it by content. The entry is appended to the journal as soon as the
+// write succeeds, so an interrupted run can still be undone. A failed write
+// leaves no entry behind.
+func (j *Journal) Record(path, content string, write func() error) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return errors.NewFileError("invalid path", path, "journal")
+	}
+	original, err := os.ReadFile(abs)
+	if err != nil {
+		return errors.NewFileError("failed to read file", path, "journal")
+	}
+
+	j.mu.Lock()
+	if j.ID == "" {
+		if err := j.create(); err != nil {
+			j.mu.Unlock()
+			return err
+		}
+	}
+	j.backups++
+	entry := Entry{
+		Path:   abs,
+		Backup: fmt.Sprintf("%04d", j.backups),
+		Before: Hash(string(original)),
+		After:  Hash(content),
+	}
+	backup := filepath.Join(j.dir, entry.Backup)
+	j.mu.Unlock()
+
+	if err := os.WriteFile(backup, original, 0600); err != nil {
+		return errors.NewFileError("failed to write backup", path, "journal")
+	}
+	if err := write(); err != nil {
+		os.Remove(backup)
+		return err
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if err := j.append(entry); err != nil {
+		return err
+	}
+	j.Files = append(j.Files, entry)
+	return nil
+}
+
+// create makes a new run directory named after the start time of the run
+func (j *Journal) create() error {
+	if err := os.MkdirAll(j.dir, 0755); err != nil {
+		return errors.NewFileError("failed to create state directory", j.dir, "journal")
+	}
+	// Keep the state directory out of git status
+	ignore := filepath.Join(filepath.Dir(j.dir), ".gitignore")
+	if _, err := os.Stat(ignore); os.IsNotExist(err) {
+		os.WriteFile(ignore, []byte("*\n"), 0644)
+	}
+	base := j.Created.Format("20060102-150405")
+	for i := 1; ; i++ {
+		id := base
+		if i > 1 {
+			id = fmt.Sprintf("%s-%d", base, i)
+		}
+		err := os.Mkdir(filepath.Join(j.dir, id), 0700)
+		if os.IsExist(err) {
+			continue
+		}
+		if err != nil {
+			return errors.NewFileError("failed to create run directory", j.dir, "journal")
+		}
+		j.ID = id
+		j.dir = filepath.Join(j.dir, id)
+		return j.writeHeader()
+	}
+}
+
+// writeHeader writes the id, command and start time of the run
+func (j *Journal) writeHeader() error {
+	data, err := json.MarshalIndent(j, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(j.dir, journalFile), data, 0600); err != nil {
+		return errors.NewFileError("failed to write journal", j.dir, "journal")
+	}
+	return nil
+}
+
+// append adds an entry to the file list of the run
+func (j *Journal) append(entry Entry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(filepath.Join(j.dir, entriesFile), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return errors.NewFileError("failed to write journal", j.dir, "journal")
+	}
+	_, err = f.Write(append(line, '\n'))
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return errors.NewFileError("failed to write journal", j.dir, "journal")
+	}
+	return nil
+}
+
+// List returns the recorded runs in stateDir, oldest first
+func List(stateDir string) ([]*Journal, error) {
+	dir := filepath.Join(stateDir, runsDir)
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.NewFileError("failed to read state directory", dir, "journal")
+	}
+
+	var runs []*Journal
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		j, err := Load(stateDir, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, j)
+	}
+	sort.Slice(runs, func(a, b int) bool {
+		if !runs[a].Created.Equal(runs[b].Created) {
+			return runs[a].Created.Before(runs[b].Created)
+		}
+		return runs[a].ID < runs[b].ID
+	})
+	return runs, nil
+}
+
+// Load reads the journal of a run
+func Load(stateDir, id string) (*Journal, error) {
+	dir := filepath.Join(stateDir, runsDir, id)
+	data, err := os.ReadFile(filepath.Join(dir, journalFile))
+	if err != nil {
+		return nil, errors.NewFileError("no journal for run "+id, dir, "journal")
+	}
+	j := &Journal{dir: dir}
+	if err := json.Unmarshal(data, j); err != nil {
+		return nil, errors.NewFileError("invalid journal: "+err.Error(), dir, "journal")
+	}
+
+	data, err = os.ReadFile(filepath.Join(dir, entriesFile))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, errors.NewFileError("failed to read journal", dir, "journal")
+	}
+	lines := strings.Split(string(data), "\n")
+	for i, line := range lines {
+		if line == "" {
+			continue
+		}
+		var entry Entry
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			if i == len(lines)-1 {
+				break // cut short by an interrupted run, its file was not written
+			}
+			return nil, errors.NewFileError("invalid journal: "+err.Error(), dir, "journal")
+		}
+		j.Files = append(j.Files, entry)
+	}
+	return j, nil
+}
+
+// Prune removes all but the newest keep runs in stateDir
+func Prune(stateDir string, keep int) error {
+	runs, err := List(stateDir)
+	if err != nil {
+		return err
+	}
+	for len(runs) > keep {
+		if err := runs[0].Remove(); err != nil {
+			return err
+		}
+		runs = runs[1:]
+	}
+	return nil
+}
+
+// Changed returns the files that no longer hold the content the run wrote
+func (j *Journal) Changed() []string {
+	var changed []string
+	for _, entry := range j.Files {
+		content, err := os.ReadFile(entry.Path)
+		if err != nil || Hash(string(content)) != entry.After {
+			changed = append(changed, entry.Path)
+		}
+	}
+	return changed
+}
+
+// Original returns the content a file had before the run
+func (j *Journal) Original(entry Entry) (string, error) {
+	content, err := os.ReadFile(filepath.Join(j.dir, entry.Backup))
+	if err != nil || Hash(string(content)) != entry.Before {
+		return "", errors.NewFileError("backup is missing or damaged", entry.Path, "undo")
+	}
+	return string(content), nil
+}
+
+// Remove deletes the run and its backups
+func (j *Journal) Remove() error {
+	if err := os.RemoveAll(j.dir); err != nil {
+		return errors.NewFileError("failed to remove run", j.dir, "undo")
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package journal
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// noWrite stands in for writes that leave the file as it is
+func noWrite() error { return nil }
+
+func TestJournal(t *testing.T) {
+	dir := t.TempDir()
+	state := filepath.Join(dir, ".state")
+	file := filepath.Join(dir, "main.go")
+	if err := os.WriteFile(file, []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if runs, err := List(state); err != nil || len(runs) != 0 {
+		t.Fatalf("List() = %v, %v before any run", runs, err)
+	}
+
+	j := New(state, "add")
+	if err := j.Record(file, "// header\npackage main\n", func() error {
+		return os.WriteFile(file, []byte("// header\npackage main\n"), 0644)
+	}); err != nil {
+		t.Fatalf("Record() failed: %v", err)
+	}
+	New(state, "update") // runs without changes leave nothing behind
+
+	runs, err := List(state)
+	if err != nil || len(runs) != 1 {
+		t.Fatalf("List() = %v, %v, want one run", runs, err)
+	}
+	run := runs[0]
+	if run.ID != j.ID || run.Command != "add" || len(run.Files) != 1 {
+		t.Fatalf("loaded run = %+v", run)
+	}
+	if changed := run.Changed(); len(changed) != 0 {
+		t.Errorf("Changed() = %v for an untouched file", changed)
+	}
+	original, err := run.Original(run.Files[0])
+	if err != nil || original != "package main\n" {
+		t.Errorf("Original() = %q, %v", original, err)
+	}
+
+	if err := os.WriteFile(file, []byte("// edited\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if changed := run.Changed(); len(changed) != 1 || changed[0] != file {
+		t.Errorf("Changed() = %v, want %s", changed, file)
+	}
+
+	if err := run.Remove(); err != nil {
+		t.Fatalf("Remove() failed: %v", err)
+	}
+	if runs, _ := List(state); len(runs) != 0 {
+		t.Errorf("List() = %v after Remove()", runs)
+	}
+	if _, err := os.Stat(filepath.Join(state, ".gitignore")); err != nil {
+		t.Errorf("state directory has no .gitignore: %v", err)
+	}
+}
+
+func TestInterruptedRun(t *testing.T) {
+	dir := t.TempDir()
+	state := filepath.Join(dir, ".state")
+	file := filepath.Join(dir, "main.go")
+	if err := os.WriteFile(file, []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	j := New(state, "add")
+	if err := j.Record(file, "// header\npackage main\n", noWrite); err != nil {
+		t.Fatalf("Record() failed: %v", err)
+	}
+	// A run killed while appending leaves a partial last line
+	f, err := os.OpenFile(filepath.Join(j.dir, entriesFile), os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"path":"/cut`)
+	f.Close()
+
+	run, err := Load(state, j.ID)
+	if err != nil || len(run.Files) != 1 || run.Files[0].Path != file {
+		t.Errorf("Load() = %+v, %v, want the complete entry", run, err)
+	}
+}
+
+func TestFailedWrite(t *testing.T) {
+	dir := t.TempDir()
+	state := filepath.Join(dir, ".state")
+	file := filepath.Join(dir, "main.go")
+	if err := os.WriteFile(file, []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	j := New(state, "add")
+	failed := errors.New("disk full")
+	if err := j.Record(file, "// header\npackage main\n", func() error { return failed }); err != failed {
+		t.Fatalf("Record() = %v, want the write error", err)
+	}
+	if err := j.Record(file, "package main\n", noWrite); err != nil {
+		t.Fatalf("Record() failed: %v", err)
+	}
+
+	// Only the file that was written is undone, and nothing reports it changed
+	run, err := Load(state, j.ID)
+	if err != nil || len(run.Files) != 1 || run.Files[0].After != Hash("package main\n") {
+		t.Fatalf("Load() = %+v, %v, want only the successful write", run, err)
+	}
+	if changed := run.Changed(); len(changed) != 0 {
+		t.Errorf("Changed() = %v after a failed write", changed)
+	}
+	backups, _ := filepath.Glob(filepath.Join(j.dir, "0*"))
+	if len(backups) != 1 {
+		t.Errorf("backups = %v, want only the one of the successful write", backups)
+	}
+}
+
+func TestPrune(t *testing.T) {
+	dir := t.TempDir()
+	state := filepath.Join(dir, ".state")
+	file := filepath.Join(dir, "main.go")
+	if err := os.WriteFile(file, []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var ids []string
+	for i := 0; i < 4; i++ {
+		j := New(state, "add")
+		if err := j.Record(file, "package main\n", noWrite); err != nil {
+			t.Fatalf("Record() failed: %v", err)
+		}
+		ids = append(ids, j.ID)
+	}
+
+	if err := Prune(state, 2); err != nil {
+		t.Fatalf("Prune() failed: %v", err)
+	}
+	runs, err := List(state)
+	if err != nil || len(runs) != 2 || runs[0].ID != ids[2] || runs[1].ID != ids[3] {
+		t.Errorf("List() = %v, %v, want the runs %v", runs, err, ids[2:])
+	}
+}
@@ -0,0 +1,84 @@
+package language
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/jeeftor/license-manager/internal/styles"
+)
+
+// GeneratedLines is how many lines at the start of a file are searched for
+// generated-code markers
+const GeneratedLines = 20
+
+// DefaultGeneratedPatterns match the comments that standard code generators
+// write at the top of their output, in every language
+var DefaultGeneratedPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`^Code generated .* DO NOT EDIT\.$`),                           // Go convention
+	regexp.MustCompile(`(^|\s)@generated\b`),                                          // Buck, Hack, Java annotation processors
+	regexp.MustCompile(`^Generated by the protocol buffer compiler\.\s+DO NOT EDIT!`), // protoc
+	regexp.MustCompile(`(?i)auto[- ]?generated by (the )?(OpenAPI Generator|swagger code generator)`),
+	regexp.MustCompile(`(?i)^Generated by:? https?://openapi-generator\.tech`),
+	regexp.MustCompile(`^<auto-generated`), // .NET tools
+	regexp.MustCompile(`(?i)^(this (file|code) (is|was) )?auto-?generated\b.*\bdo not (edit|modify)\b`),
+}
+
+// CommentLine is the text of one comment line, without comment syntax
+type CommentLine struct {
+	Line int // 0-based line number
+	Text string
+}
+
+// CommentLines returns the comments in the first n lines of content, without
+// the comment syntax of the language and license markers
+func (h *GenericHandler) CommentLines(content string, style styles.CommentLanguage, n int) []CommentLine {
+	var comments []CommentLine
+	blockPrefix := strings.TrimSpace(style.MultiPrefix)
+	inBlock := false
+
+	for i, line := range strings.Split(content, "\n") {
+		if i >= n {
+			break
+		}
+		text := strings.TrimSpace(stripMarkers(line))
+
+		switch {
+		case inBlock:
+			if before, _, found := strings.Cut(text, style.MultiEnd); found {
+				text, inBlock = before, false
+			}
+			if blockPrefix != "" {
+				text = strings.TrimPrefix(text, blockPrefix)
+			}
+		case style.Single != "" && strings.HasPrefix(text, style.Single):
+			text = strings.TrimPrefix(text, style.Single)
+		case style.MultiStart != "" && strings.HasPrefix(text, style.MultiStart):
+			text = strings.TrimPrefix(text, style.MultiStart)
+			if blockPrefix != "" {
+				text = strings.TrimLeft(text, blockPrefix) // /** javadoc
+			}
+			if before, _, found := strings.Cut(text, style.MultiEnd); found {
+				text = before
+			} else {
+				inBlock = true
+			}
+		default:
+			continue
+		}
+		comments = append(comments, CommentLine{Line: i, Text: strings.TrimSpace(text)})
+	}
+	return comments
+}
+
+// MatchComment returns the text of the first comment that matches one of the
+// patterns, or ""
+func MatchComment(comments []CommentLine, patterns []*regexp.Regexp) string {
+	for _, comment := range comments {
+		for _, pattern := range patterns {
+			if pattern.MatchString(comment.Text) {
+				return comment.Text
+			}
+		}
+	}
+	return ""
+}
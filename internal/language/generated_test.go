@@ -0,0 +1,44 @@
+package language
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jeeftor/license-manager/internal/logger"
+	"github.com/jeeftor/license-manager/internal/styles"
+)
+
+func TestGeneratedMarkers(t *testing.T) {
+	tests := []struct {
+		name    string
+		ext     string
+		content string
+		want    string
+	}{
+		{"go", "go", "// Code generated by protoc-gen-go. DO NOT EDIT.\n// source: api.proto\n\npackage api\n",
+			"Code generated by protoc-gen-go. DO NOT EDIT."},
+		{"go after build tags", "go", "//go:build linux\n\n// Code generated by stringer; DO NOT EDIT.\n\npackage x\n",
+			"Code generated by stringer; DO NOT EDIT."},
+		{"protoc python", "py", "# -*- coding: utf-8 -*-\n# Generated by the protocol buffer compiler.  DO NOT EDIT!\n",
+			"Generated by the protocol buffer compiler.  DO NOT EDIT!"},
+		{"javadoc", "java", "/**\n * Service client.\n *\n * @generated\n */\nclass A {}\n", "@generated"},
+		{"one line block", "js", "/* @generated by relay-compiler */\nexport {}\n", "@generated by relay-compiler"},
+		{"dotnet", "cs", "//------\n// <auto-generated>\n//     This code was generated by a tool.\n", "<auto-generated>"},
+		{"openapi", "ts", "/**\n * NOTE: This class is auto generated by OpenAPI Generator (https://openapi-generator.tech).\n */\n",
+			"NOTE: This class is auto generated by OpenAPI Generator (https://openapi-generator.tech)."},
+		{"hand written", "go", "// Package x does not edit files.\npackage x\n", ""},
+		{"marker in code", "go", "package x\n\nconst s = \"Code generated by x. DO NOT EDIT.\"\n", ""},
+		{"too far down", "go", "package x\n" + strings.Repeat("\n", GeneratedLines) + "// Code generated by x. DO NOT EDIT.\n", ""},
+	}
+
+	log := logger.NewLogger(logger.ErrorLevel)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := GetLanguageHandler(log, tt.ext, styles.Get("hash"))
+			comments := h.CommentLines(tt.content, styles.GetLanguageCommentStyle(tt.ext), GeneratedLines)
+			if got := MatchComment(comments, DefaultGeneratedPatterns); got != tt.want {
+				t.Errorf("MatchComment() = %q, want %q (comments %q)", got, tt.want, comments)
+			}
+		})
+	}
+}
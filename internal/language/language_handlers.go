@@ -25,6 +25,9 @@ type LanguageHandler interface {
 
 	// ExtractComponents extracts all components from the content including preamble, license parts, and remaining content
 	ExtractComponents(content string) (components ExtractedComponents, success bool)
+
+	// CommentLines returns the comments in the first n lines of content
+	CommentLines(content string, commentStyle styles.CommentLanguage, n int) []CommentLine
 }
 
 // ExtractedComponents
@@ -0,0 +1,61 @@
+package language
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/jeeftor/license-manager/internal/styles"
+)
+
+// Pragmas that exclude a file from licensing, written as a comment
+// "license-manager: <pragma>" near the top of the file
+const (
+	PragmaIgnore          = "ignore"            // Never license the file
+	PragmaIgnoreNextBlock = "ignore-next-block" // The comment block that follows is under another license
+)
+
+// PragmaLines is how many lines at the start of a file are searched for pragmas
+const PragmaLines = 20
+
+// pragmaPattern matches a pragma in comment text
+var pragmaPattern = regexp.MustCompile(`^license-manager:\s*(ignore-next-block|ignore)\b`)
+
+// FindPragma returns the pragma in the comments of the first PragmaLines lines
+// of content, or "". An ignore-next-block pragma only applies when a comment
+// block follows it, applies is false for a pragma left behind after its block
+// was removed.
+func FindPragma(
+	h LanguageHandler,
+	content string,
+	style styles.CommentLanguage,
+) (pragma string, applies bool) {
+	for _, comment := range h.CommentLines(content, style, PragmaLines) {
+		match := pragmaPattern.FindStringSubmatch(comment.Text)
+		if match == nil {
+			continue
+		}
+		if match[1] == PragmaIgnore {
+			return match[1], true
+		}
+		return match[1], commentFollows(h, content, style, comment.Line)
+	}
+	return "", false
+}
+
+// commentFollows reports whether the first non-blank line after line is a comment
+func commentFollows(h LanguageHandler, content string, style styles.CommentLanguage, line int) bool {
+	lines := strings.Split(content, "\n")
+	next := line + 1
+	for next < len(lines) && strings.TrimSpace(lines[next]) == "" {
+		next++
+	}
+	if next >= len(lines) {
+		return false
+	}
+	for _, comment := range h.CommentLines(content, style, next+1) {
+		if comment.Line == next {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,40 @@
+package language
+
+import (
+	"testing"
+
+	"github.com/jeeftor/license-manager/internal/logger"
+	"github.com/jeeftor/license-manager/internal/styles"
+)
+
+func TestFindPragma(t *testing.T) {
+	tests := []struct {
+		name    string
+		ext     string
+		content string
+		pragma  string
+		applies bool
+	}{
+		{"go", "go", "// license-manager: ignore\npackage fixtures\n", PragmaIgnore, true},
+		{"python after shebang", "py", "#!/usr/bin/env python\n# license-manager: ignore (byte-exact fixture)\n", PragmaIgnore, true},
+		{"block comment", "java", "/*\n * license-manager: ignore\n */\nclass A {}\n", PragmaIgnore, true},
+		{"html", "html", "<!-- license-manager: ignore -->\n<p>x</p>\n", PragmaIgnore, true},
+		{"next block", "js", "// license-manager: ignore-next-block\n\n/*\n * Copyright Other Corp, Apache-2.0\n */\nvar a;\n",
+			PragmaIgnoreNextBlock, true},
+		{"next block removed", "js", "// license-manager: ignore-next-block\nvar a;\n", PragmaIgnoreNextBlock, false},
+		{"in code", "go", "package x\n\nconst s = \"license-manager: ignore\"\n", "", false},
+		{"mentioned in prose", "go", "// Run license-manager: it ignores nothing\npackage x\n", "", false},
+		{"none", "go", "package x\n", "", false},
+	}
+
+	log := logger.NewLogger(logger.ErrorLevel)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := GetLanguageHandler(log, tt.ext, styles.Get("hash"))
+			pragma, applies := FindPragma(h, tt.content, styles.GetLanguageCommentStyle(tt.ext))
+			if pragma != tt.pragma || applies != tt.applies {
+				t.Errorf("FindPragma() = %q, %v, want %q, %v", pragma, applies, tt.pragma, tt.applies)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package license
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/jeeftor/license-manager/internal/language"
+	"github.com/jeeftor/license-manager/internal/styles"
+)
+
+// utf8BOM is the UTF-8 encoded byte order mark
+const utf8BOM = "\uFEFF"
+
+// TextFormat is the line ending and byte order mark convention of a file
+type TextFormat struct {
+	BOM  bool // Content starts with a UTF-8 byte order mark
+	CRLF bool // Every line ends with \r\n
+}
+
+// NormalizeText strips a UTF-8 byte order mark and turns \r\n line endings into
+// \n, and returns the format that restores the original text. Files that mix
+// line endings are left as they are, so their untouched lines keep their bytes.
+func NormalizeText(content string) (string, TextFormat) {
+	var format TextFormat
+	if strings.HasPrefix(content, utf8BOM) {
+		format.BOM = true
+		content = content[len(utf8BOM):]
+	}
+
+	lines := strings.Count(content, "\n")
+	if lines > 0 && strings.Count(content, "\r\n") == lines {
+		format.CRLF = true
+		content = strings.ReplaceAll(content, "\r\n", "\n")
+	}
+	return content, format
+}
+
+// Apply converts normalized text back to the format
+func (f TextFormat) Apply(text string) string {
+	if f.CRLF {
+		text = strings.ReplaceAll(text, "\n", "\r\n")
+	}
+	if f.BOM {
+		text = utf8BOM + text
+	}
+	return text
+}
+
+// String describes the format for log output
+func (f TextFormat) String() string {
+	parts := []string{"LF"}
+	if f.CRLF {
+		parts[0] = "CRLF"
+	}
+	if f.BOM {
+		parts = append(parts, "UTF-8 BOM")
+	}
+	return strings.Join(parts, ", ")
+}
+
+// StripMarkers removes the zero-width markers of license headers, for files in
+// encodings that cannot represent them
+func StripMarkers(content string) string {
+	content = strings.ReplaceAll(content, language.MarkerStart, "")
+	return strings.ReplaceAll(content, language.MarkerEnd, "")
+}
+
+// restoreMarkers undoes StripMarkers: it wraps a header line of a preset style
+// and the next footer line in markers, trying each candidate in turn until
+// accept takes the result. Content that already has markers, or no accepted
+// header and footer, is returned unchanged.
+func restoreMarkers(content string, accept func(marked string, style styles.HeaderFooterStyle) bool) string {
+	if strings.Contains(content, language.MarkerStart) {
+		return content
+	}
+
+	lines := strings.Split(content, "\n")
+	for _, name := range styles.List() {
+		style := styles.Get(name)
+		for i := range lines {
+			header, ok := markLine(lines[i], style.Header)
+			if !ok {
+				continue
+			}
+			for j := i + 1; j < len(lines); j++ {
+				footer, ok := markLine(lines[j], style.Footer)
+				if !ok {
+					continue
+				}
+				marked := slices.Clone(lines)
+				marked[i], marked[j] = header, footer
+				if result := strings.Join(marked, "\n"); accept(result, style) {
+					return result
+				}
+				break
+			}
+		}
+	}
+	return content
+}
+
+// markLine wraps text in markers when the line is only a comment prefix
+// followed by text
+func markLine(line, text string) (string, bool) {
+	i := strings.LastIndex(line, text)
+	if i < 0 || strings.TrimSpace(line[i+len(text):]) != "" {
+		return "", false
+	}
+	if strings.IndexFunc(line[:i], func(r rune) bool {
+		return unicode.IsLetter(r) || unicode.IsDigit(r)
+	}) >= 0 {
+		return "", false
+	}
+	return line[:i] + language.MarkerStart + text + language.MarkerEnd + line[i+len(text):], true
+}
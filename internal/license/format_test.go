@@ -0,0 +1,97 @@
+package license
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jeeftor/license-manager/internal/logger"
+	"github.com/jeeftor/license-manager/internal/styles"
+)
+
+func TestNormalizeText(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+		format  TextFormat
+	}{
+		{"lf", "a\nb\n", "a\nb\n", TextFormat{}},
+		{"crlf", "a\r\nb\r\n", "a\nb\n", TextFormat{CRLF: true}},
+		{"bom", "\uFEFF<?xml?>\n", "<?xml?>\n", TextFormat{BOM: true}},
+		{"bom and crlf", "\uFEFF#!/bin/sh\r\necho\r\n", "#!/bin/sh\necho\n", TextFormat{BOM: true, CRLF: true}},
+		{"mixed endings", "a\r\nb\nc\r\n", "a\r\nb\nc\r\n", TextFormat{}},
+		{"single line", "a", "a", TextFormat{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, format := NormalizeText(tt.content)
+			if got != tt.want || format != tt.format {
+				t.Fatalf("NormalizeText() = %q, %+v, want %q, %+v", got, format, tt.want, tt.format)
+			}
+			if restored := format.Apply(got); restored != tt.content {
+				t.Errorf("Apply() = %q, want %q", restored, tt.content)
+			}
+		})
+	}
+}
+
+func TestRestoreMarkers(t *testing.T) {
+	log := logger.NewLogger(logger.ErrorLevel)
+	licenseText := "Copyright (c) 2025 Acme Corp\nAll rights reserved."
+	newManager := func(ext, style, content string) *LicenseManager {
+		m := NewLicenseManager(log, licenseText, ext, styles.Get(style), styles.GetLanguageCommentStyle(ext))
+		m.SetFileContent(content)
+		return m
+	}
+	licensed := func(ext, style, content string) string {
+		m := newManager(ext, style, content)
+		_, components := m.HasLicense(content)
+		result, err := m.AddLicense(components, styles.GetLanguageCommentStyle(ext).Language)
+		if err != nil {
+			t.Fatalf("AddLicense() failed: %v", err)
+		}
+		return result
+	}
+	banner := "/*\n * ----------------------------------------\n * Module: café parser\n * ----------------------------------------\n */\n"
+
+	tests := []struct {
+		name    string
+		ext     string
+		style   string
+		content string
+	}{
+		{"single line comments", ".py", "hash", licensed(".py", "hash", "print()\n")},
+		{"block comment", ".c", "simple", licensed(".c", "simple", "int x;\n")},
+		{"before a divider banner", ".c", "simple", licensed(".c", "simple", banner+"int x;\n")},
+		{"divider banner", ".c", "simple", banner + "int x;\n"},
+		{"no license", ".c", "simple", "int x;\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newManager(tt.ext, tt.style, StripMarkers(tt.content))
+			m.RestoreMarkers()
+			if m.FileContent != tt.content {
+				t.Errorf("RestoreMarkers() = %q, want %q", m.FileContent, tt.content)
+			}
+		})
+	}
+
+	// Only the years differ, so the header is still ours
+	outdated := strings.Replace(licensed(".py", "hash", "print()\n"), "2025", "2019", 1)
+	m := newManager(".py", "hash", StripMarkers(outdated))
+	m.RestoreMarkers()
+	if m.FileContent != outdated {
+		t.Errorf("RestoreMarkers() = %q, want %q", m.FileContent, outdated)
+	}
+
+	// Code that merely looks like a header line is not marked
+	hash := "######################################"
+	code := "x = \"" + hash + "\"\ny = \"" + hash + "\"\n"
+	m = newManager(".py", "hash", code)
+	m.RestoreMarkers()
+	if m.FileContent != code {
+		t.Errorf("RestoreMarkers() marked code: %q", m.FileContent)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"reflect"
 	"strings"
 
+	"github.com/jeeftor/license-manager/internal/diff"
 	"github.com/jeeftor/license-manager/internal/errors"
 	"github.com/jeeftor/license-manager/internal/language"
 	"github.com/jeeftor/license-manager/internal/logger"
@@ -24,6 +25,9 @@ const (
 	ContentMismatch
 	// StyleMismatch indicates that the license content matches but the style is different
 	StyleMismatch
+	// OutdatedYear indicates that the license only differs in its copyright years.
+	// It skips 5, which check uses as the exit code for processing errors.
+	OutdatedYear Status = iota + 1
 )
 
 func (s Status) String() string {
@@ -38,11 +42,33 @@ func (s Status) String() string {
 		return "License style mismatch"
 	case ContentAndStyleMismatch:
 		return "License content and style mismatch"
+	case OutdatedYear:
+		return "Outdated copyright year"
 	default:
 		return "Unknown status"
 	}
 }
 
+// Name returns the identifier of the status used in reports, e.g. "NoLicense"
+func (s Status) Name() string {
+	switch s {
+	case FullMatch:
+		return "FullMatch"
+	case NoLicense:
+		return "NoLicense"
+	case ContentMismatch:
+		return "ContentMismatch"
+	case StyleMismatch:
+		return "StyleMismatch"
+	case ContentAndStyleMismatch:
+		return "ContentAndStyleMismatch"
+	case OutdatedYear:
+		return "OutdatedYear"
+	default:
+		return "Unknown"
+	}
+}
+
 // LicenseManager handles license operations
 type LicenseManager struct {
 	licenseTemplate   string
@@ -51,9 +77,19 @@ type LicenseManager struct {
 	langHandler       language.LanguageHandler
 	logger            *logger.Logger
 	InitialComponents *language.ExtractedComponents
-	HasInitialLicense bool // did we detect a license at startup of the manager
+	HasInitialLicense bool   // did we detect a license at startup of the manager
+	DetectedStyle     string // name of the header style found by SearchForLicense, if any
 	//todo: Should we rename this variable later
-	FileContent string
+	FileContent  string     // Normalized by SetFileContent, see Restore
+	textFormat   TextFormat // Line endings and BOM of the file
+	yearTolerant bool       // ignore copyright year differences when comparing bodies
+	yearChecked  bool       // report OutdatedYear when bodies only differ in copyright years
+
+	markersRequired bool // only blocks with markers are licenses, set by RestoreMarkers
+
+	// Bodies compared by the last CheckLicenseStatus call
+	expectedBody string
+	actualBody   string
 }
 
 // NewLicenseManager creates a new manager
@@ -91,7 +127,7 @@ func (m *LicenseManager) SearchForLicense(content string) ScanResults {
 	m.logger.LogInfo("  SearchForLicense::Analyzing license block...")
 	m.logger.LogInfo("  SearchForLicense::Using comment style: %s", m.commentStyle.Language)
 
-	components, success := m.langHandler.ExtractComponents(content)
+	components, success := m.extractComponents(content)
 
 	// Handle special case for c-go style headers first
 	if m.commentStyle.Language == "go" && strings.HasPrefix(components.Header, "#include") {
@@ -172,6 +208,10 @@ func (m *LicenseManager) SearchForLicense(content string) ScanResults {
 		}
 	}
 
+	if analysis.IsStyleMatch {
+		m.DetectedStyle = analysis.Style.Name
+	}
+
 	return analysis
 }
 
@@ -220,7 +260,7 @@ func (m *LicenseManager) HasLicense(content string) (bool, *language.ExtractedCo
 	m.logger.LogInfo("  HasLicense::Using comment style: %s", m.commentStyle.Language)
 
 	//components, success := m.langHandler.ExtractComponents(content)
-	components, success := m.langHandler.ExtractComponents(content)
+	components, success := m.extractComponents(content)
 
 	// Store results for later use
 	m.InitialComponents = &components
@@ -396,7 +436,7 @@ func (m *LicenseManager) CheckLicenseStatus(content string) Status {
 	m.logger.LogInfo("CheckLicenseStatus:Checking license status...")
 	m.logger.LogInfo("CheckLicenseStatus:Using comment style: %s", m.commentStyle.Language)
 
-	actualExtract, success := handler.ExtractComponents(content)
+	actualExtract, success := m.extractComponents(content)
 
 	if actualExtract.Preamble != "" {
 		m.logger.LogInfo(
@@ -410,7 +450,7 @@ func (m *LicenseManager) CheckLicenseStatus(content string) Status {
 		success = m.HasInitialLicense
 	} else {
 		// Only extract if we don't have stored results
-		actualExtract, success = handler.ExtractComponents(content)
+		actualExtract, success = m.extractComponents(content)
 		m.InitialComponents = &actualExtract
 		m.HasInitialLicense = success
 	}
@@ -439,6 +479,7 @@ func (m *LicenseManager) CheckLicenseStatus(content string) Status {
 
 	actualBody := actualExtract.Body
 	expectedBody := expectedExtract.Body
+	m.expectedBody, m.actualBody = expectedBody, actualBody
 
 	// If headers don't match
 	if m.headerStyle.Name != "" && m.headerStyle.Name != detectedStyle.Name {
@@ -449,7 +490,7 @@ func (m *LicenseManager) CheckLicenseStatus(content string) Status {
 			detectedStyle.Name,
 		)
 
-		if actualBody == expectedBody {
+		if m.bodiesMatch(actualBody, expectedBody) || m.yearsOnlyDiffer(actualBody, expectedBody) {
 			// We body match w/out same headers
 			return StyleMismatch
 		}
@@ -457,9 +498,13 @@ func (m *LicenseManager) CheckLicenseStatus(content string) Status {
 		return ContentAndStyleMismatch
 	}
 
-	if actualBody == expectedBody {
+	if m.bodiesMatch(actualBody, expectedBody) {
 		return FullMatch
 	}
+	if m.yearsOnlyDiffer(actualBody, expectedBody) {
+		m.logger.LogInfo("Result: License copyright years differ from expected")
+		return OutdatedYear
+	}
 
 	// Handle mismatch
 	m.logger.LogInfo("Result: License content differs from expected")
@@ -467,12 +512,43 @@ func (m *LicenseManager) CheckLicenseStatus(content string) Status {
 	return ContentMismatch
 }
 
+// bodiesMatch compares license bodies, ignoring copyright years when year tolerance is enabled.
+// SPDX short-form headers are compared by their tags rather than as text.
+func (m *LicenseManager) bodiesMatch(actual, expected string) bool {
+	if actual == expected {
+		return true
+	}
+	if expectedTags, ok := ParseSPDX(expected); ok {
+		actualTags, _ := ParseSPDX(actual)
+		m.logger.LogInfo("Comparing SPDX tags: expected %q, found %q", expectedTags.Expression, actualTags.Expression)
+		return expectedTags.Matches(actualTags, m.yearTolerant)
+	}
+	if m.yearTolerant && MaskYears(actual) == MaskYears(expected) {
+		m.logger.LogInfo("License body only differs in copyright years")
+		return true
+	}
+	return false
+}
+
+// yearsOnlyDiffer reports whether license bodies only differ in copyright years,
+// when years are checked
+func (m *LicenseManager) yearsOnlyDiffer(actual, expected string) bool {
+	if !m.yearChecked {
+		return false
+	}
+	if expectedTags, ok := ParseSPDX(expected); ok {
+		actualTags, _ := ParseSPDX(actual)
+		return expectedTags.Matches(actualTags, true)
+	}
+	return MaskCopyrightYears(actual) == MaskCopyrightYears(expected)
+}
+
 func (m *LicenseManager) logDiff(expected, current string) {
 	m.logger.LogInfo("BodySize current[%d] expected[%d]",
 		strings.Count(current, "\n"),
 		strings.Count(expected, "\n"))
-	m.logger.LogInfo(" current: %x", []byte(current))
-	m.logger.LogInfo("expected: %x", []byte(expected))
+	m.logger.LogDebug(" current: %x", []byte(current))
+	m.logger.LogDebug("expected: %x", []byte(expected))
 
 	lines1 := strings.Split(expected, "\n")
 	lines2 := strings.Split(current, "\n")
@@ -497,6 +573,12 @@ func (m *LicenseManager) logDiff(expected, current string) {
 	}
 }
 
+// ContentDiff returns a unified diff from the expected to the actual license text
+// compared by the last CheckLicenseStatus call, or "" when they are identical
+func (m *LicenseManager) ContentDiff() string {
+	return diff.Unified("expected", "actual", m.expectedBody+"\n", m.actualBody+"\n", diff.DefaultContext)
+}
+
 // FormatLicenseForFile formats the license text with the current comment style
 func (m *LicenseManager) FormatLicenseForFile(text string) string {
 	if m.commentStyle.Language == "" {
@@ -564,6 +646,62 @@ func (m *LicenseManager) detectHeaderStyle(
 	return m.headerStyle
 }
 
+// HeaderLines returns the 1-based line range of the license header and footer
+// in the file content. Without a license it returns the line where one would be added.
+func (m *LicenseManager) HeaderLines() (start, end int) {
+	lines := strings.Split(m.FileContent, "\n")
+	for i, line := range lines {
+		if !strings.Contains(line, language.MarkerStart) || !strings.Contains(line, language.MarkerEnd) {
+			continue
+		}
+		if start == 0 {
+			start = i + 1
+			continue
+		}
+		return start, i + 1
+	}
+	if start != 0 {
+		return start, start
+	}
+
+	// Licenses are added after the preamble (shebang, build tags, ...)
+	line := 1
+	if m.InitialComponents != nil && m.InitialComponents.Preamble != "" {
+		line += strings.Count(m.InitialComponents.Preamble, "\n") + 1
+	}
+	if line > len(lines) {
+		line = len(lines)
+	}
+	return line, line
+}
+
+// CommentLines returns the comments in the first n lines of the file
+func (m *LicenseManager) CommentLines(n int) []language.CommentLine {
+	return m.langHandler.CommentLines(m.FileContent, m.commentStyle, n)
+}
+
+// Pragma returns the license-manager pragma of the file, see language.FindPragma
+func (m *LicenseManager) Pragma() (pragma string, applies bool) {
+	return language.FindPragma(m.langHandler, m.FileContent, m.commentStyle)
+}
+
+// HandlerName returns the type name of the language handler, e.g. "GoHandler"
+func (m *LicenseManager) HandlerName() string {
+	t := reflect.TypeOf(m.langHandler)
+	if t == nil {
+		return ""
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Name()
+}
+
+// GetLicenseTemplate returns the license text this manager formats into headers
+func (m *LicenseManager) GetLicenseTemplate() string {
+	return m.licenseTemplate
+}
+
 // GetHeaderStyle returns the current header style
 func (m *LicenseManager) GetHeaderStyle() styles.HeaderFooterStyle {
 	return m.headerStyle
@@ -573,8 +711,59 @@ func (m *LicenseManager) SetHeaderStyle(style styles.HeaderFooterStyle) {
 	m.headerStyle = style
 }
 
+// SetFileContent sets the file content in its normalized form, with \n line
+// endings and without a byte order mark. Restore converts results back.
 func (m *LicenseManager) SetFileContent(content string) {
-	m.FileContent = content
+	m.FileContent, m.textFormat = NormalizeText(content)
+}
+
+// RestoreMarkers puts back the markers that StripMarkers removed from the file
+// content, around the first block that holds this manager's license, copyright
+// years aside. Other comment banners drawn like a preset style stay unmarked,
+// and from then on only blocks with markers count as a license.
+func (m *LicenseManager) RestoreMarkers() {
+	m.FileContent = restoreMarkers(m.FileContent, func(marked string, style styles.HeaderFooterStyle) bool {
+		actual, ok := m.langHandler.ExtractComponents(marked)
+		if !ok {
+			return false
+		}
+		expected, _ := m.langHandler.ExtractComponents(language.FormatComment(m.licenseTemplate, m.commentStyle, style))
+		return m.bodiesMatch(actual.Body, expected.Body) || MaskYears(actual.Body) == MaskYears(expected.Body)
+	})
+	m.markersRequired = true
+}
+
+// extractComponents splits content into preamble, license block and the rest,
+// ignoring blocks without markers when they are required
+func (m *LicenseManager) extractComponents(content string) (language.ExtractedComponents, bool) {
+	components, ok := m.langHandler.ExtractComponents(content)
+	if ok && m.markersRequired && !strings.Contains(content, language.MarkerStart) {
+		preamble, rest := m.langHandler.PreservePreamble(content)
+		return language.ExtractedComponents{Preamble: preamble, Rest: rest}, false
+	}
+	return components, ok
+}
+
+// Restore converts normalized content back to the line endings and byte order
+// mark of the file passed to SetFileContent
+func (m *LicenseManager) Restore(content string) string {
+	return m.textFormat.Apply(content)
+}
+
+// TextFormat returns the line endings and byte order mark of the file
+func (m *LicenseManager) TextFormat() TextFormat {
+	return m.textFormat
+}
+
+// SetYearTolerant makes license checks ignore differences in copyright years
+func (m *LicenseManager) SetYearTolerant(tolerant bool) {
+	m.yearTolerant = tolerant
+}
+
+// SetYearChecked makes license checks report OutdatedYear when the license only
+// differs in its copyright years
+func (m *LicenseManager) SetYearChecked(checked bool) {
+	m.yearChecked = checked
 }
 
 // helper function to truncate strings for logging
@@ -0,0 +1,115 @@
+package license
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/jeeftor/license-manager/internal/licenses"
+)
+
+// SPDX short-form tags, see https://spdx.github.io/spdx-spec/v2.3/using-SPDX-short-identifiers-in-source-files/
+const (
+	SPDXLicenseTag   = "SPDX-License-Identifier:"
+	SPDXCopyrightTag = "SPDX-FileCopyrightText:"
+)
+
+// SPDXTags holds the tags of an SPDX short-form license header
+type SPDXTags struct {
+	Copyrights []string // Values of the SPDX-FileCopyrightText tags
+	Expression string   // Value of the SPDX-License-Identifier tag
+}
+
+// SPDXTemplate returns the license template for an SPDX short-form header.
+// The copyright line is only rendered when a holder is set.
+func SPDXTemplate(expression string) string {
+	return "{{if .Holder}}" + SPDXCopyrightTag + " {{.Year}} {{.Holder}}\n{{end}}" +
+		SPDXLicenseTag + " " + expression
+}
+
+// FindSPDXIdentifier returns the license expression of the first
+// SPDX-License-Identifier tag in text, or "" when there is none
+func FindSPDXIdentifier(text string) string {
+	for _, line := range strings.Split(text, "\n") {
+		if idx := strings.Index(line, SPDXLicenseTag); idx >= 0 {
+			return strings.TrimSpace(line[idx+len(SPDXLicenseTag):])
+		}
+	}
+	return ""
+}
+
+// ParseSPDX parses a license body consisting only of SPDX tags.
+// It reports false when the body contains other text or no license identifier.
+func ParseSPDX(body string) (SPDXTags, bool) {
+	var tags SPDXTags
+	for _, line := range strings.Split(body, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "":
+			continue
+		case strings.HasPrefix(line, SPDXLicenseTag):
+			if tags.Expression != "" {
+				return tags, false
+			}
+			tags.Expression = strings.TrimSpace(strings.TrimPrefix(line, SPDXLicenseTag))
+		case strings.HasPrefix(line, SPDXCopyrightTag):
+			tags.Copyrights = append(tags.Copyrights, strings.TrimSpace(strings.TrimPrefix(line, SPDXCopyrightTag)))
+		default:
+			return tags, false
+		}
+	}
+	return tags, tags.Expression != ""
+}
+
+// Matches reports whether the actual tags satisfy t: the license
+// expressions are equivalent and every copyright of t is present.
+// With yearTolerant set, copyright years are ignored.
+func (t SPDXTags) Matches(actual SPDXTags, yearTolerant bool) bool {
+	if normalizeExpression(t.Expression) != normalizeExpression(actual.Expression) {
+		return false
+	}
+
+	present := make(map[string]bool, len(actual.Copyrights))
+	for _, c := range actual.Copyrights {
+		present[normalizeCopyright(c, yearTolerant)] = true
+	}
+	for _, c := range t.Copyrights {
+		if !present[normalizeCopyright(c, yearTolerant)] {
+			return false
+		}
+	}
+	return true
+}
+
+// expressionToken splits a license expression into parentheses and words
+var expressionToken = regexp.MustCompile(`[()]|[^\s()]+`)
+
+// normalizeExpression canonicalizes an SPDX license expression: operators are
+// upper-cased, known identifiers and their aliases map to the catalog id and
+// the remaining identifiers are compared case-insensitively
+func normalizeExpression(expr string) string {
+	tokens := expressionToken.FindAllString(expr, -1)
+	for i, tok := range tokens {
+		switch upper := strings.ToUpper(tok); upper {
+		case "AND", "OR", "WITH", "(", ")":
+			tokens[i] = upper
+		default:
+			if lic, ok := licenses.Lookup(tok); ok {
+				tok = lic.ID
+			}
+			tokens[i] = strings.ToLower(tok)
+		}
+	}
+	return strings.Join(tokens, " ")
+}
+
+// copyrightPrefix matches the optional "Copyright" / "(c)" / "©" lead-in of a copyright text
+var copyrightPrefix = regexp.MustCompile(`(?i)^((copyright|\(c\)|©)\s*)+`)
+
+// normalizeCopyright reduces a copyright text to its years and holder
+func normalizeCopyright(text string, yearTolerant bool) string {
+	text = copyrightPrefix.ReplaceAllString(strings.TrimSpace(text), "")
+	if yearTolerant {
+		text = MaskYears(text)
+	}
+	return strings.Join(strings.Fields(text), " ")
+}
@@ -0,0 +1,125 @@
+package license
+
+import (
+	"testing"
+
+	"github.com/jeeftor/license-manager/internal/logger"
+	"github.com/jeeftor/license-manager/internal/styles"
+)
+
+func TestParseSPDX(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		wantOK bool
+		want   SPDXTags
+	}{
+		{
+			name:   "identifier and copyright",
+			body:   "SPDX-FileCopyrightText: 2025 Acme Corp\nSPDX-License-Identifier: MIT",
+			wantOK: true,
+			want:   SPDXTags{Copyrights: []string{"2025 Acme Corp"}, Expression: "MIT"},
+		},
+		{
+			name:   "blank lines and indentation",
+			body:   "\n  SPDX-License-Identifier: Apache-2.0 OR MIT  \n",
+			wantOK: true,
+			want:   SPDXTags{Expression: "Apache-2.0 OR MIT"},
+		},
+		{
+			name:   "full license text",
+			body:   "SPDX-License-Identifier: MIT\nPermission is hereby granted",
+			wantOK: false,
+		},
+		{
+			name:   "copyright only",
+			body:   "SPDX-FileCopyrightText: 2025 Acme Corp",
+			wantOK: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ParseSPDX(tt.body)
+			if ok != tt.wantOK {
+				t.Fatalf("ParseSPDX() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+			if got.Expression != tt.want.Expression || len(got.Copyrights) != len(tt.want.Copyrights) {
+				t.Fatalf("ParseSPDX() = %+v, want %+v", got, tt.want)
+			}
+			for i := range got.Copyrights {
+				if got.Copyrights[i] != tt.want.Copyrights[i] {
+					t.Errorf("ParseSPDX() copyright %d = %q, want %q", i, got.Copyrights[i], tt.want.Copyrights[i])
+				}
+			}
+		})
+	}
+}
+
+func TestSPDXTagsMatches(t *testing.T) {
+	expected := SPDXTags{Copyrights: []string{"2026 Acme Corp"}, Expression: "Apache-2.0 OR MIT"}
+
+	tests := []struct {
+		name         string
+		actual       SPDXTags
+		yearTolerant bool
+		want         bool
+	}{
+		{"identical", expected, false, true},
+		{"case and spacing", SPDXTags{Copyrights: []string{"2026  Acme Corp"}, Expression: "apache-2.0 or  mit"}, false, true},
+		{"copyright prefix", SPDXTags{Copyrights: []string{"Copyright (c) 2026 Acme Corp"}, Expression: "Apache-2.0 OR MIT"}, false, true},
+		{"additional holder", SPDXTags{Copyrights: []string{"2024 Jane Doe", "2026 Acme Corp"}, Expression: "Apache-2.0 OR MIT"}, false, true},
+		{"other license", SPDXTags{Copyrights: []string{"2026 Acme Corp"}, Expression: "Apache-2.0 AND MIT"}, false, false},
+		{"other holder", SPDXTags{Copyrights: []string{"2026 Other Inc"}, Expression: "Apache-2.0 OR MIT"}, false, false},
+		{"missing copyright", SPDXTags{Expression: "Apache-2.0 OR MIT"}, false, false},
+		{"old year", SPDXTags{Copyrights: []string{"2019-2025 Acme Corp"}, Expression: "Apache-2.0 OR MIT"}, false, false},
+		{"old year tolerated", SPDXTags{Copyrights: []string{"2019-2025 Acme Corp"}, Expression: "Apache-2.0 OR MIT"}, true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := expected.Matches(tt.actual, tt.yearTolerant); got != tt.want {
+				t.Errorf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckLicenseStatusSPDX(t *testing.T) {
+	log := logger.NewLogger(logger.ErrorLevel)
+	headerStyle := styles.Get("hash")
+	data := TemplateData{Year: "2026", Holder: "Acme Corp"}
+
+	expected, err := RenderTemplate(SPDXTemplate("Apache-2.0"), data)
+	if err != nil {
+		t.Fatalf("RenderTemplate() failed: %v", err)
+	}
+
+	for _, ext := range []string{".go", ".py", ".sh", ".rb", ".yaml", ".rs", ".php", ".css", ".html", ".xml"} {
+		commentStyle := styles.GetLanguageCommentStyle(ext)
+
+		// A header with equivalent tags written in a different order and case
+		written := "SPDX-License-Identifier: apache-2.0\nSPDX-FileCopyrightText: Copyright 2026 Acme Corp"
+		writer := NewLicenseManager(log, written, ext, headerStyle, commentStyle)
+		writer.SearchForLicense("content\n")
+		content, err := writer.AddLicense(writer.InitialComponents, commentStyle.Language)
+		if err != nil {
+			t.Fatalf("%s: AddLicense() failed: %v", ext, err)
+		}
+
+		checker := NewLicenseManager(log, expected, ext, headerStyle, commentStyle)
+		checker.SearchForLicense(content)
+		if got := checker.CheckLicenseStatus(content); got != FullMatch {
+			t.Errorf("%s: CheckLicenseStatus() = %v, want %v", ext, got, FullMatch)
+		}
+
+		other := NewLicenseManager(log, "SPDX-License-Identifier: MIT", ext, headerStyle, commentStyle)
+		other.SearchForLicense(content)
+		if got := other.CheckLicenseStatus(content); got != ContentMismatch {
+			t.Errorf("%s: CheckLicenseStatus() with other license = %v, want %v", ext, got, ContentMismatch)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package license
+
+import (
+	"os"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/jeeftor/license-manager/internal/errors"
+)
+
+// TemplateData holds the values available to license text templates
+type TemplateData struct {
+	Year     string            // Copyright year, e.g. "2025"
+	Holder   string            // Copyright holder
+	RelPath  string            // Path of the file relative to the working directory
+	FileName string            // Base name of the file
+	Language string            // Language of the file, e.g. "go"
+	Vars     map[string]string // Custom values from the config file or --var
+}
+
+// templateFuncs are the helper functions available inside license templates
+var templateFuncs = template.FuncMap{
+	"env":   os.Getenv,
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"default": func(fallback, value string) string {
+		if value == "" {
+			return fallback
+		}
+		return value
+	},
+}
+
+// RenderTemplate renders license text as a text/template with the given data.
+// Text without template actions is returned unchanged.
+func RenderTemplate(text string, data TemplateData) (string, error) {
+	if !strings.Contains(text, "{{") {
+		return text, nil
+	}
+
+	tmpl, err := template.New("license").Funcs(templateFuncs).Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return "", errors.NewLicenseError("invalid license template: "+err.Error(), "")
+	}
+
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return "", errors.NewLicenseError("failed to render license template: "+err.Error(), "")
+	}
+	return sb.String(), nil
+}
+
+// UsesYear reports whether a license template references the year
+func UsesYear(text string) bool {
+	return strings.Contains(text, ".Year")
+}
+
+// yearPattern matches a single year or a year range such as 2019-2025
+var yearPattern = regexp.MustCompile(`\b(19|20)\d{2}(\s*[-–]\s*(19|20)\d{2})?\b`)
+
+// MaskYears replaces every year and year range with a placeholder so that
+// headers can be compared independently of their copyright years
+func MaskYears(text string) string {
+	return yearPattern.ReplaceAllString(text, "YYYY")
+}
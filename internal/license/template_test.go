@@ -0,0 +1,92 @@
+package license
+
+import (
+	"testing"
+
+	"github.com/jeeftor/license-manager/internal/logger"
+	"github.com/jeeftor/license-manager/internal/styles"
+)
+
+func TestRenderTemplate(t *testing.T) {
+	data := TemplateData{
+		Year:     "2026",
+		Holder:   "Acme Corp",
+		RelPath:  "pkg/util/strings.go",
+		FileName: "strings.go",
+		Language: "go",
+		Vars:     map[string]string{"project": "rocket"},
+	}
+
+	tests := []struct {
+		name     string
+		template string
+		want     string
+		wantErr  bool
+	}{
+		{
+			name:     "plain text is unchanged",
+			template: "Copyright (c) 2025 Test Corp",
+			want:     "Copyright (c) 2025 Test Corp",
+		},
+		{
+			name:     "year and holder",
+			template: "Copyright (c) {{.Year}} {{.Holder}}",
+			want:     "Copyright (c) 2026 Acme Corp",
+		},
+		{
+			name:     "file values",
+			template: "{{.FileName}} ({{.Language}}) at {{.RelPath}}",
+			want:     "strings.go (go) at pkg/util/strings.go",
+		},
+		{
+			name:     "custom vars and helpers",
+			template: `{{upper .Vars.project}} {{default "n/a" .Vars.missing}}`,
+			want:     "ROCKET n/a",
+		},
+		{
+			name:     "invalid template",
+			template: "Copyright {{.Year",
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RenderTemplate(tt.template, data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RenderTemplate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("RenderTemplate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckLicenseStatusYearTolerance(t *testing.T) {
+	log := logger.NewLogger(logger.ErrorLevel)
+	commentStyle := styles.GetLanguageCommentStyle(".go")
+	headerStyle := styles.Get("hash")
+
+	// A header written last year
+	writer := NewLicenseManager(log, "Copyright (c) 2025 Acme Corp\nAll rights reserved.", ".go", headerStyle, commentStyle)
+	writer.SearchForLicense("package main\n")
+	content, err := writer.AddLicense(writer.InitialComponents, "go")
+	if err != nil {
+		t.Fatalf("AddLicense() failed: %v", err)
+	}
+
+	for _, tolerant := range []bool{false, true} {
+		checker := NewLicenseManager(log, "Copyright (c) 2026 Acme Corp\nAll rights reserved.", ".go", headerStyle, commentStyle)
+		checker.SetYearTolerant(tolerant)
+		checker.SearchForLicense(content)
+
+		want := ContentMismatch
+		if tolerant {
+			want = FullMatch
+		}
+		if got := checker.CheckLicenseStatus(content); got != want {
+			t.Errorf("tolerant=%v: CheckLicenseStatus() = %v, want %v", tolerant, got, want)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package license
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jeeftor/license-manager/internal/errors"
+)
+
+// Year policies decide the {{.Year}} of each file and how check treats other years
+const (
+	YearAny         = "any"          // Current year for new headers, any year passes check
+	YearCurrent     = "current"      // Current year
+	YearFirstCommit = "first-commit" // Year of the first commit of the file
+	YearCommitRange = "commit-range" // First to last commit year, e.g. 2019-2025
+)
+
+// YearPolicies lists the supported year policies
+func YearPolicies() []string {
+	return []string{YearAny, YearCurrent, YearFirstCommit, YearCommitRange}
+}
+
+// ValidateYearPolicy returns an error for unknown year policies
+func ValidateYearPolicy(policy string) error {
+	for _, p := range YearPolicies() {
+		if policy == p {
+			return nil
+		}
+	}
+	return errors.NewValidationError(
+		fmt.Sprintf("unknown year policy %q (supported: %s)", policy, strings.Join(YearPolicies(), ", ")),
+		"YearPolicy")
+}
+
+// PolicyYear returns the {{.Year}} value for a policy. first and last are the
+// years of the first and last commit of the file, or 0 when it has no commits,
+// in which case the current year is used.
+func PolicyYear(policy string, current string, first, last int) string {
+	if current == "" {
+		current = strconv.Itoa(time.Now().Year())
+	}
+	if first == 0 {
+		return current
+	}
+
+	switch policy {
+	case YearFirstCommit:
+		return strconv.Itoa(first)
+	case YearCommitRange:
+		if first == last {
+			return strconv.Itoa(first)
+		}
+		return fmt.Sprintf("%d-%d", first, last)
+	default:
+		return current
+	}
+}
+
+// copyrightLine matches lines that state a copyright, including SPDX-FileCopyrightText
+var copyrightLine = regexp.MustCompile(`(?i)copyright|\(c\)|©`)
+
+// MaskCopyrightYears is like MaskYears but only masks years on copyright lines,
+// so dates inside the license text itself (e.g. "January 2004") still count
+func MaskCopyrightYears(text string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if copyrightLine.MatchString(line) {
+			lines[i] = yearPattern.ReplaceAllString(line, "YYYY")
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// copyrightYears returns the years and year ranges on copyright lines, in order
+func copyrightYears(text string) []string {
+	var years []string
+	for _, line := range strings.Split(text, "\n") {
+		if copyrightLine.MatchString(line) {
+			years = append(years, yearPattern.FindAllString(line, -1)...)
+		}
+	}
+	return years
+}
+
+// UpdateYears returns the file content with the copyright years of the existing
+// license header replaced by the expected ones. Everything else, including the
+// comment formatting of the header, is left unchanged. It must be called after
+// CheckLicenseStatus reported OutdatedYear.
+func (m *LicenseManager) UpdateYears() (string, error) {
+	expected := copyrightYears(m.expectedBody)
+	actual := copyrightYears(m.actualBody)
+	if len(expected) == 0 || len(expected) != len(actual) {
+		return "", errors.NewLicenseError("copyright years of the header do not line up with the license", "")
+	}
+
+	start, end := m.HeaderLines()
+	lines := strings.Split(m.FileContent, "\n")
+	next := 0
+	for i := start - 1; i < end && i < len(lines); i++ {
+		if !copyrightLine.MatchString(lines[i]) {
+			continue
+		}
+		lines[i] = yearPattern.ReplaceAllStringFunc(lines[i], func(year string) string {
+			if next >= len(actual) || year != actual[next] {
+				next = len(actual) + 1 // out of step with the parsed header
+				return year
+			}
+			next++
+			return expected[next-1]
+		})
+	}
+	if next != len(actual) {
+		return "", errors.NewLicenseError("copyright years not found in the header lines", "")
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// BumpYear extends a copyright year or year range to end at year, e.g. "2024"
+// becomes "2024-2026" and "2019 - 2024" becomes "2019 - 2026". Tokens that
+// already reach year are returned unchanged.
+func BumpYear(token string, year int) string {
+	if len(token) < 4 {
+		return token
+	}
+	last, err := strconv.Atoi(token[len(token)-4:])
+	if err != nil || last >= year {
+		return token
+	}
+	if len(token) == 4 {
+		return fmt.Sprintf("%s-%d", token, year)
+	}
+	return fmt.Sprintf("%s%d", token[:len(token)-4], year)
+}
+
+// BumpYears returns the file content with every copyright year of the managed
+// license header extended to year by BumpYear. Only year tokens change, the rest
+// of the file stays byte-identical, and a header without a copyright year is
+// left as it is. It must be called after SearchForLicense.
+func (m *LicenseManager) BumpYears(year int) (string, error) {
+	if !m.HasInitialLicense {
+		return "", errors.NewLicenseError("no managed license header found", "")
+	}
+
+	start, end := m.HeaderLines()
+	lines := strings.Split(m.FileContent, "\n")
+	found := false
+	for i := start - 1; i < end && i < len(lines); i++ {
+		if !copyrightLine.MatchString(lines[i]) {
+			continue
+		}
+		lines[i] = yearPattern.ReplaceAllStringFunc(lines[i], func(token string) string {
+			found = true
+			return BumpYear(token, year)
+		})
+	}
+	if !found {
+		return m.FileContent, nil
+	}
+	return strings.Join(lines, "\n"), nil
+}
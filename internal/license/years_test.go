@@ -0,0 +1,154 @@
+package license
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jeeftor/license-manager/internal/logger"
+	"github.com/jeeftor/license-manager/internal/styles"
+)
+
+func TestPolicyYear(t *testing.T) {
+	tests := []struct {
+		policy      string
+		current     string
+		first, last int
+		want        string
+	}{
+		{YearAny, "2026", 2019, 2025, "2026"},
+		{YearCurrent, "2026", 2019, 2025, "2026"},
+		{YearFirstCommit, "2026", 2019, 2025, "2019"},
+		{YearCommitRange, "2026", 2019, 2025, "2019-2025"},
+		{YearCommitRange, "2026", 2025, 2025, "2025"},
+		{YearFirstCommit, "2026", 0, 0, "2026"}, // not committed yet
+	}
+
+	for _, tt := range tests {
+		if got := PolicyYear(tt.policy, tt.current, tt.first, tt.last); got != tt.want {
+			t.Errorf("PolicyYear(%q, %q, %d, %d) = %q, want %q", tt.policy, tt.current, tt.first, tt.last, got, tt.want)
+		}
+	}
+
+	if err := ValidateYearPolicy("latest"); err == nil {
+		t.Error("ValidateYearPolicy() should reject unknown policies")
+	}
+}
+
+func TestMaskCopyrightYears(t *testing.T) {
+	text := "Copyright (c) 2019-2025 Acme\nVersion 2.0, January 2004\nSPDX-FileCopyrightText: 2024 Acme"
+	want := "Copyright (c) YYYY Acme\nVersion 2.0, January 2004\nSPDX-FileCopyrightText: YYYY Acme"
+	if got := MaskCopyrightYears(text); got != want {
+		t.Errorf("MaskCopyrightYears() = %q, want %q", got, want)
+	}
+}
+
+func TestOutdatedYear(t *testing.T) {
+	log := logger.NewLogger(logger.ErrorLevel)
+	commentStyle := styles.GetLanguageCommentStyle(".go")
+	headerStyle := styles.Get("hash")
+	const text = "Copyright (c) %s Acme Corp\n\nLicensed under the Apache License, Version 2.0, January 2004"
+
+	render := func(year string) string {
+		return strings.Replace(text, "%s", year, 1)
+	}
+
+	writer := NewLicenseManager(log, render("2019"), ".go", headerStyle, commentStyle)
+	writer.SearchForLicense("package main\n")
+	content, err := writer.AddLicense(writer.InitialComponents, commentStyle.Language)
+	if err != nil {
+		t.Fatalf("AddLicense() failed: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		expected string
+		checked  bool
+		want     Status
+	}{
+		{"same year", render("2019"), true, FullMatch},
+		{"newer range", render("2019-2025"), true, OutdatedYear},
+		{"years not checked", render("2019-2025"), false, ContentMismatch},
+		{"other license date", strings.Replace(render("2019"), "2004", "2005", 1), true, ContentMismatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewLicenseManager(log, tt.expected, ".go", headerStyle, commentStyle)
+			m.SetYearChecked(tt.checked)
+			m.SetFileContent(content)
+			m.SearchForLicense(content)
+			if got := m.CheckLicenseStatus(content); got != tt.want {
+				t.Fatalf("CheckLicenseStatus() = %v, want %v", got, tt.want)
+			}
+			if tt.want != OutdatedYear {
+				return
+			}
+
+			updated, err := m.UpdateYears()
+			if err != nil {
+				t.Fatalf("UpdateYears() failed: %v", err)
+			}
+			if want := strings.Replace(content, "(c) 2019 ", "(c) 2019-2025 ", 1); updated != want {
+				t.Errorf("UpdateYears() changed more than the year:\n%s\nwant\n%s", updated, want)
+			}
+		})
+	}
+}
+
+func TestBumpYears(t *testing.T) {
+	tests := []struct {
+		token string
+		want  string
+	}{
+		{"2024", "2024-2026"},
+		{"2019-2024", "2019-2026"},
+		{"2019 – 2024", "2019 – 2026"},
+		{"2026", "2026"},
+		{"2019-2026", "2019-2026"},
+	}
+	for _, tt := range tests {
+		if got := BumpYear(tt.token, 2026); got != tt.want {
+			t.Errorf("BumpYear(%q) = %q, want %q", tt.token, got, tt.want)
+		}
+	}
+
+	log := logger.NewLogger(logger.ErrorLevel)
+	commentStyle := styles.GetLanguageCommentStyle(".go")
+	headerStyle := styles.Get("hash")
+	const text = "Copyright (c) 2024 Acme Corp\n\nLicensed under the Apache License, Version 2.0, January 2004"
+
+	writer := NewLicenseManager(log, text, ".go", headerStyle, commentStyle)
+	writer.SearchForLicense("package main\n\n// Built in 2020\n")
+	content, err := writer.AddLicense(writer.InitialComponents, commentStyle.Language)
+	if err != nil {
+		t.Fatalf("AddLicense() failed: %v", err)
+	}
+
+	m := NewLicenseManager(log, "", ".go", headerStyle, commentStyle)
+	m.SetFileContent(content)
+	m.SearchForLicense(content)
+	bumped, err := m.BumpYears(2026)
+	if err != nil {
+		t.Fatalf("BumpYears() failed: %v", err)
+	}
+	if want := strings.Replace(content, "(c) 2024 ", "(c) 2024-2026 ", 1); bumped != want {
+		t.Errorf("BumpYears() changed more than the year:\n%s\nwant\n%s", bumped, want)
+	}
+
+	// Headers without a copyright year are left alone
+	writer = NewLicenseManager(log, "Licensed under the Apache License, Version 2.0", ".go", headerStyle, commentStyle)
+	writer.SearchForLicense("package main\n")
+	content, err = writer.AddLicense(writer.InitialComponents, commentStyle.Language)
+	if err != nil {
+		t.Fatalf("AddLicense() failed: %v", err)
+	}
+	m.SetFileContent(content)
+	m.SearchForLicense(content)
+	if bumped, err := m.BumpYears(2026); err != nil || bumped != content {
+		t.Errorf("BumpYears() = %q, %v for a header without a year, want it unchanged", bumped, err)
+	}
+
+	m.SearchForLicense("package main\n")
+	if _, err := m.BumpYears(2026); err == nil {
+		t.Error("BumpYears() should fail without a managed header")
+	}
+}
@@ -0,0 +1,99 @@
+// Package licenses provides the embedded catalog of standard license headers
+package licenses
+
+import (
+	"embed"
+	"sort"
+	"strings"
+
+	"github.com/jeeftor/license-manager/internal/errors"
+)
+
+//go:embed texts/*.txt
+var texts embed.FS
+
+// License describes a license header shipped with the binary
+type License struct {
+	ID   string // SPDX license identifier
+	Name string // Human readable name
+	file string // File name inside texts/
+}
+
+// catalog lists the embedded licenses keyed by lower-cased SPDX identifier
+var catalog = map[string]License{
+	"mit":                    {ID: "MIT", Name: "MIT License", file: "mit.txt"},
+	"apache-2.0":             {ID: "Apache-2.0", Name: "Apache License 2.0", file: "apache-2.0.txt"},
+	"bsd-2-clause":           {ID: "BSD-2-Clause", Name: `BSD 2-Clause "Simplified" License`, file: "bsd-2-clause.txt"},
+	"bsd-3-clause":           {ID: "BSD-3-Clause", Name: `BSD 3-Clause "New" or "Revised" License`, file: "bsd-3-clause.txt"},
+	"mpl-2.0":                {ID: "MPL-2.0", Name: "Mozilla Public License 2.0", file: "mpl-2.0.txt"},
+	"gpl-2.0-only":           {ID: "GPL-2.0-only", Name: "GNU General Public License v2.0 only", file: "gpl-2.0-only.txt"},
+	"gpl-2.0-or-later":       {ID: "GPL-2.0-or-later", Name: "GNU General Public License v2.0 or later", file: "gpl-2.0-or-later.txt"},
+	"gpl-3.0-only":           {ID: "GPL-3.0-only", Name: "GNU General Public License v3.0 only", file: "gpl-3.0-only.txt"},
+	"gpl-3.0-or-later":       {ID: "GPL-3.0-or-later", Name: "GNU General Public License v3.0 or later", file: "gpl-3.0-or-later.txt"},
+	"lgpl-2.1-only":          {ID: "LGPL-2.1-only", Name: "GNU Lesser General Public License v2.1 only", file: "lgpl-2.1-only.txt"},
+	"lgpl-2.1-or-later":      {ID: "LGPL-2.1-or-later", Name: "GNU Lesser General Public License v2.1 or later", file: "lgpl-2.1-or-later.txt"},
+	"lgpl-3.0-only":          {ID: "LGPL-3.0-only", Name: "GNU Lesser General Public License v3.0 only", file: "lgpl-3.0-only.txt"},
+	"lgpl-3.0-or-later":      {ID: "LGPL-3.0-or-later", Name: "GNU Lesser General Public License v3.0 or later", file: "lgpl-3.0-or-later.txt"},
+	"agpl-3.0-only":          {ID: "AGPL-3.0-only", Name: "GNU Affero General Public License v3.0 only", file: "agpl-3.0-only.txt"},
+	"agpl-3.0-or-later":      {ID: "AGPL-3.0-or-later", Name: "GNU Affero General Public License v3.0 or later", file: "agpl-3.0-or-later.txt"},
+	"isc":                    {ID: "ISC", Name: "ISC License", file: "isc.txt"},
+	"unlicense":              {ID: "Unlicense", Name: "The Unlicense", file: "unlicense.txt"},
+	"licenseref-proprietary": {ID: "LicenseRef-Proprietary", Name: "Proprietary (all rights reserved)", file: "proprietary.txt"},
+}
+
+// aliases maps common short names and deprecated SPDX identifiers to catalog keys
+var aliases = map[string]string{
+	"apache":      "apache-2.0",
+	"bsd":         "bsd-3-clause",
+	"mpl":         "mpl-2.0",
+	"gpl":         "gpl-3.0-or-later",
+	"gpl-2.0":     "gpl-2.0-only",
+	"gpl-2.0+":    "gpl-2.0-or-later",
+	"gpl-3.0":     "gpl-3.0-only",
+	"gpl-3.0+":    "gpl-3.0-or-later",
+	"lgpl-2.1":    "lgpl-2.1-only",
+	"lgpl-2.1+":   "lgpl-2.1-or-later",
+	"lgpl-3.0":    "lgpl-3.0-only",
+	"lgpl-3.0+":   "lgpl-3.0-or-later",
+	"agpl-3.0":    "agpl-3.0-only",
+	"agpl-3.0+":   "agpl-3.0-or-later",
+	"proprietary": "licenseref-proprietary",
+}
+
+// Lookup returns the catalog entry for an SPDX identifier (case-insensitive)
+func Lookup(id string) (License, bool) {
+	key := strings.ToLower(strings.TrimSpace(id))
+	if alias, ok := aliases[key]; ok {
+		key = alias
+	}
+	lic, ok := catalog[key]
+	return lic, ok
+}
+
+// Get returns the license header template for an SPDX identifier.
+// The text may reference {{.Year}} and {{.Holder}}.
+func Get(id string) (string, error) {
+	lic, ok := Lookup(id)
+	if !ok {
+		return "", errors.NewValidationError(
+			"unknown license id "+id+" (run the licenses command for a list)", "LicenseID")
+	}
+
+	content, err := texts.ReadFile("texts/" + lic.file)
+	if err != nil {
+		return "", errors.NewFileError("failed to read embedded license", lic.file, "read")
+	}
+	return strings.TrimRight(string(content), "\n"), nil
+}
+
+// List returns all embedded licenses sorted by identifier
+func List() []License {
+	list := make([]License, 0, len(catalog))
+	for _, lic := range catalog {
+		list = append(list, lic)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return strings.ToLower(list[i].ID) < strings.ToLower(list[j].ID)
+	})
+	return list
+}
@@ -0,0 +1,77 @@
+package licenses
+
+import (
+	"strings"
+	"testing"
+	"text/template"
+)
+
+func TestCatalogTextsAreEmbedded(t *testing.T) {
+	for _, lic := range List() {
+		text, err := Get(lic.ID)
+		if err != nil {
+			t.Errorf("Get(%q) failed: %v", lic.ID, err)
+			continue
+		}
+		if strings.TrimSpace(text) == "" {
+			t.Errorf("Get(%q) returned empty text", lic.ID)
+		}
+		if strings.HasSuffix(text, "\n") {
+			t.Errorf("Get(%q) should not end with a newline", lic.ID)
+		}
+	}
+}
+
+func TestCatalogTextsWithoutHolder(t *testing.T) {
+	for _, lic := range List() {
+		text, err := Get(lic.ID)
+		if err != nil {
+			t.Fatalf("Get(%q) failed: %v", lic.ID, err)
+		}
+		tmpl, err := template.New(lic.ID).Parse(text)
+		if err != nil {
+			t.Errorf("%s does not parse: %v", lic.ID, err)
+			continue
+		}
+		var b strings.Builder
+		if err := tmpl.Execute(&b, struct{ Year, Holder string }{Year: "2025"}); err != nil {
+			t.Errorf("%s does not render: %v", lic.ID, err)
+			continue
+		}
+		for _, line := range strings.Split(b.String(), "\n") {
+			if strings.HasSuffix(line, " ") || strings.Contains(line, "2025 .") {
+				t.Errorf("%s renders %q without a holder", lic.ID, line)
+			}
+		}
+	}
+}
+
+func TestLookup(t *testing.T) {
+	tests := []struct {
+		id     string
+		wantID string
+		found  bool
+	}{
+		{id: "MIT", wantID: "MIT", found: true},
+		{id: "apache-2.0", wantID: "Apache-2.0", found: true},
+		{id: " bsd-3-clause ", wantID: "BSD-3-Clause", found: true},
+		{id: "GPL-3.0+", wantID: "GPL-3.0-or-later", found: true},
+		{id: "proprietary", wantID: "LicenseRef-Proprietary", found: true},
+		{id: "WTFPL", found: false},
+	}
+
+	for _, tt := range tests {
+		lic, ok := Lookup(tt.id)
+		if ok != tt.found {
+			t.Errorf("Lookup(%q) found = %v, want %v", tt.id, ok, tt.found)
+			continue
+		}
+		if ok && lic.ID != tt.wantID {
+			t.Errorf("Lookup(%q) = %q, want %q", tt.id, lic.ID, tt.wantID)
+		}
+	}
+
+	if _, err := Get("WTFPL"); err == nil {
+		t.Error("Get() should fail for unknown license ids")
+	}
+}
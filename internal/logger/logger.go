@@ -3,6 +3,7 @@ package logger
 import (
 	"bufio"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 
@@ -42,6 +43,7 @@ type LogLevel int
 type Logger struct {
 	colors map[string]*color.Color
 	level  LogLevel
+	out    io.Writer // nil writes to os.Stdout
 }
 
 // NewLogger creates a new Logger instance
@@ -59,6 +61,29 @@ func NewLogger(LogLevel LogLevel) *Logger {
 	}
 }
 
+// SetOutput redirects log output, e.g. to os.Stderr when stdout carries a report
+func (l *Logger) SetOutput(w io.Writer) {
+	l.out = w
+}
+
+// WithOutput returns a logger with the same level and colors that writes to w
+func (l *Logger) WithOutput(w io.Writer) *Logger {
+	return &Logger{colors: l.colors, level: l.level, out: w}
+}
+
+// Write writes already formatted log output, e.g. lines buffered by a WithOutput logger
+func (l *Logger) Write(p []byte) (int, error) {
+	return l.writer().Write(p)
+}
+
+// writer returns the log destination
+func (l *Logger) writer() io.Writer {
+	if l.out == nil {
+		return os.Stdout
+	}
+	return l.out
+}
+
 func (l *Logger) Log(level LogLevel, showPrefix bool, format string, args ...interface{}) {
 	if l.level <= level {
 		var outputText string
@@ -90,9 +115,9 @@ func (l *Logger) Log(level LogLevel, showPrefix bool, format string, args ...int
 		}
 
 		if showPrefix {
-			fmt.Printf("%s %s\n", prefix, outputText)
+			fmt.Fprintf(l.writer(), "%s %s\n", prefix, outputText)
 		} else {
-			fmt.Printf("%s\n", outputText)
+			fmt.Fprintf(l.writer(), "%s\n", outputText)
 		}
 	}
 }
@@ -140,7 +165,7 @@ func (l *Logger) LogVerbose(format string, args ...interface{}) {
 // Prompt asks the user for confirmation
 func (l *Logger) Prompt(message string) bool {
 	reader := bufio.NewReader(os.Stdin)
-	fmt.Printf("%s [y/N]: ", message)
+	fmt.Fprintf(l.writer(), "%s [y/N]: ", message)
 
 	response, err := reader.ReadString('\n')
 	if err != nil {
@@ -157,20 +182,60 @@ func (l *Logger) PrintStats(stats map[string]int, operation string) {
 		return
 	}
 
-	fmt.Println("\nSummary:")
+	w := l.writer()
+	fmt.Fprintln(w, "\nSummary:")
 	if stats["added"] > 0 {
-		fmt.Printf("%s license to %d files\n", operation, stats["added"])
+		fmt.Fprintf(w, "%s license to %d files\n", operation, stats["added"])
+	}
+	if stats["updated"] > 0 {
+		fmt.Fprintf(w, "Updated %d files\n", stats["updated"])
+	}
+	if stats["removed"] > 0 {
+		fmt.Fprintf(w, "Removed license from %d files\n", stats["removed"])
+	}
+	if stats["patched"] > 0 {
+		fmt.Fprintf(w, "Wrote changes for %d files to the patch file\n", stats["patched"])
+	}
+	if stats["copied"] > 0 {
+		fmt.Fprintf(w, "Copied %d unchanged files\n", stats["copied"])
 	}
 	if stats["existing"] > 0 {
-		fmt.Printf(
+		fmt.Fprintf(w,
 			"License already exists in %d files (use 'update' command to modify)\n",
 			stats["existing"],
 		)
 	}
 	if stats["skipped"] > 0 {
-		fmt.Printf("Skipped %d files\n", stats["skipped"])
+		fmt.Fprintf(w, "Skipped %d files\n", stats["skipped"])
+	}
+	if stats["read-only"] > 0 {
+		fmt.Fprintf(w, "Skipped %d read-only files\n", stats["read-only"])
+	}
+	if stats["pragma"] > 0 {
+		fmt.Fprintf(w, "Excluded %d files by pragma\n", stats["pragma"])
+	}
+	if stats["generated"] > 0 {
+		fmt.Fprintf(w, "Skipped %d generated files\n", stats["generated"])
+	}
+	if stats["vendored"] > 0 {
+		fmt.Fprintf(w, "Skipped %d vendored files (.gitattributes)\n", stats["vendored"])
+	}
+	if stats["binary"] > 0 {
+		fmt.Fprintf(w, "Skipped %d binary files\n", stats["binary"])
+	}
+	if stats["lfs-pointer"] > 0 {
+		fmt.Fprintf(w, "Skipped %d Git LFS pointer files\n", stats["lfs-pointer"])
+	}
+	if stats["minified"] > 0 {
+		fmt.Fprintf(w, "Skipped %d minified files\n", stats["minified"])
+	}
+	if stats["too-large"] > 0 {
+		fmt.Fprintf(w, "Skipped %d files over the size limit\n", stats["too-large"])
+	}
+	if stats["too-many-lines"] > 0 {
+		fmt.Fprintf(w, "Skipped %d files over the line limit\n", stats["too-many-lines"])
 	}
 	if stats["failed"] > 0 {
-		fmt.Printf("Failed to process %d files\n", stats["failed"])
+		fmt.Fprintf(w, "Failed to process %d files\n", stats["failed"])
 	}
 }
@@ -2,6 +2,8 @@
 package processor
 
 import (
+	"io"
+
 	"github.com/jeeftor/license-manager/internal/force"
 	"github.com/jeeftor/license-manager/internal/logger"
 )
@@ -14,12 +16,63 @@ type Config struct {
 	Skip        string // Patterns to skip
 	PresetStyle string // Header/Footer style to use
 
+	// Git integration, selected files must also match Input and not Skip
+	GitSince        string // Only files changed since the merge base with this ref
+	GitStaged       bool   // Only files staged in the index
+	GitTrackedOnly  bool   // Only files tracked by git
+	NoGitignore     bool   // Ignore .gitignore files, .licenseignore files still apply
+	NoGitattributes bool   // Process files marked generated, vendored or binary in .gitattributes
+
+	// Declared encodings of files without a byte order mark, the first matching pattern wins
+	Encodings []EncodingRule
+
+	// Generated code, found by language.DefaultGeneratedPatterns and GeneratedPatterns
+	GeneratedPolicy   string // One of the Generated* policies, GeneratedSkip when empty
+	GeneratedPatterns []GeneratedPattern
+
+	// Files over these limits are skipped, 0 for no limit
+	MaxFileSize int64 // Bytes
+	MaxLines    int
+
+	// License template values
+	Holder       string            // Copyright holder for {{.Holder}}
+	Year         string            // Year for {{.Year}}, defaults to the current year
+	YearPolicy   string            // One of the license.Year* policies, license.YearAny when empty
+	TemplateVars map[string]string // Custom values for {{.Vars.name}}
+
 	// Processing behavior
-	Prompt            bool // Whether to prompt before changes
-	DryRun            bool // Whether to show what would be done without doing it
+	Prompt            bool   // Whether to prompt before changes, processes files serially
+	Jobs              int    // Number of files processed concurrently, 0 uses all CPUs
+	DryRun            bool   // Whether to show what would be done without doing it
+	ShowDiff          bool   // Print a unified diff of every change
+	PatchFile         string // Write changes to this patch file instead of the files
+	PatchFormat       string // PatchFormatPatch (default) or PatchFormatMbox
+	OutputDir         string // Write changed files under this directory instead of in place
+	CopyUnchanged     bool   // Also copy files without changes to OutputDir
+	StateDir          string // Directory of the undo journal, "" disables it
 	LogLevel          logger.LogLevel
-	IgnoreFail        bool // Whether to return success even if checks fail
+	LogOutput         io.Writer // Destination of log lines, defaults to stdout
+	IgnoreFail        bool      // Whether to return success even if checks fail
 	ForceCommentStyle force.ForceCommentStyle
 
 	IsPreCommit bool
+
+	// Per-path overrides, the first rule with a matching pattern wins
+	Rules   []Rule
+	RuleDir string // Directory rule patterns are relative to, the working directory when empty
+}
+
+// EncodingRule declares the encoding of files matching Pattern (same syntax as Skip)
+type EncodingRule struct {
+	Pattern  string
+	Encoding string // One of charset.Names()
+}
+
+// Rule overrides the license settings for files matching one of its patterns.
+// Empty fields fall back to the top-level Config values.
+type Rule struct {
+	Patterns          []string // Glob patterns (same syntax as Skip)
+	LicenseText       string   // License text used for matching files
+	PresetStyle       string   // Header/Footer style used for matching files
+	ForceCommentStyle force.ForceCommentStyle
 }
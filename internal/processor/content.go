@@ -0,0 +1,103 @@
+package processor
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jeeftor/license-manager/internal/ignore"
+)
+
+// Skip reasons found by looking at the content of a file
+const (
+	skipBinary     = ignore.Binary // Same reason as binary files marked in .gitattributes
+	skipLFSPointer = "lfs-pointer"
+	skipMinified   = "minified"
+	skipTooLarge   = "too-large"
+	skipTooLong    = "too-many-lines"
+)
+
+const (
+	sniffLength        = 8000 // Bytes searched for NUL, as git does
+	lfsPointerMaxSize  = 1024 // Git LFS pointer files are a few lines of text
+	minifiedLineLength = 1000 // Lines longer than this are not written by hand
+)
+
+// lfsPointerPrefix starts every Git LFS pointer file
+var lfsPointerPrefix = []byte("version https://git-lfs.github.com/spec/")
+
+// SetLimits sets the size in bytes and the number of lines above which files
+// are skipped, 0 for no limit
+func (fh *FileHandler) SetLimits(maxSize int64, maxLines int) {
+	fh.maxSize = maxSize
+	fh.maxLines = maxLines
+}
+
+// ContentSkip returns why the content of a file should not be licensed:
+// it is binary, a Git LFS pointer, minified, or over the size or line limit.
+// It returns "" for files to process, including files it cannot read.
+func (fh *FileHandler) ContentSkip(path string) string {
+	info, err := os.Stat(path)
+	if err != nil {
+		return ""
+	}
+	if fh.maxSize > 0 && info.Size() > fh.maxSize {
+		return skipTooLarge
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	switch {
+	case len(data) <= lfsPointerMaxSize && bytes.HasPrefix(data, lfsPointerPrefix):
+		return skipLFSPointer
+	case fh.binary(path, data):
+		return skipBinary
+	case fh.maxLines > 0 && lineCount(data) > fh.maxLines:
+		return skipTooLong
+	case minified(path, data):
+		return skipMinified
+	}
+	return ""
+}
+
+// binary reports whether data has a NUL byte near the start. UTF-16 files,
+// with a byte order mark or a declared encoding, are text.
+func (fh *FileHandler) binary(path string, data []byte) bool {
+	if bytes.HasPrefix(data, []byte{0xFF, 0xFE}) || bytes.HasPrefix(data, []byte{0xFE, 0xFF}) {
+		return false
+	}
+	if fh.declaredEncoding(path) != "" {
+		return false
+	}
+	return bytes.IndexByte(data[:min(len(data), sniffLength)], 0) >= 0
+}
+
+// minified reports whether a file is named like a minified bundle (app.min.js,
+// style-min.css) or most of its content is in very long lines
+func minified(path string, data []byte) bool {
+	name := strings.ToLower(filepath.Base(path))
+	if strings.Contains(name, ".min.") || strings.Contains(name, "-min.") {
+		return true
+	}
+
+	long := 0
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		if len(line) > minifiedLineLength {
+			long += len(line)
+		}
+	}
+	return long*2 > len(data)
+}
+
+// lineCount returns the number of lines in data, counting a last line without
+// a newline
+func lineCount(data []byte) int {
+	lines := bytes.Count(data, []byte("\n"))
+	if len(data) > 0 && data[len(data)-1] != '\n' {
+		lines++
+	}
+	return lines
+}
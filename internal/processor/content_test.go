@@ -0,0 +1,67 @@
+package processor
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jeeftor/license-manager/internal/logger"
+)
+
+func TestContentSkips(t *testing.T) {
+	h := NewTestHelper(t, "Copyright (c) 2025 Acme Corp")
+	bundle := "var a=1;" + strings.Repeat("b(),", 500) + "\n"
+	files := map[string]string{
+		"image.c":    "GIF89a\x00\x01\x00",
+		"model.py":   "version https://git-lfs.github.com/spec/v1\noid sha256:4d7a\nsize 12345\n",
+		"app.min.js": "var a=1;\n",
+		"bundle.js":  "/* header */\n" + bundle,
+		"big.go":     "package big\n" + strings.Repeat("// filler\n", 500),
+		"long.py":    strings.Repeat("x = 1\n", 20),
+		"utf16.go":   "\xff\xfep\x00\n\x00",
+		"main.go":    "package main\n",
+	}
+	for name, content := range files {
+		h.CreateFile(name, content)
+	}
+
+	cfg := &Config{
+		LicenseText: h.LicenseText(),
+		Input:       filepath.Join(h.TmpDir(), "*.*"),
+		Skip:        "LICENSE",
+		PresetStyle: "hash",
+		LogLevel:    logger.ErrorLevel,
+		MaxFileSize: 4096,
+		MaxLines:    10,
+	}
+	p := NewFileProcessor(cfg)
+	if err := p.Add(); err != nil {
+		t.Fatalf("Add() failed: %v", err)
+	}
+
+	want := map[string]string{
+		"image.c":    skipBinary,
+		"model.py":   skipLFSPointer,
+		"app.min.js": skipMinified,
+		"bundle.js":  skipMinified,
+		"big.go":     skipTooLarge,
+		"long.py":    skipTooLong,
+	}
+	for _, rec := range p.Records() {
+		name := filepath.Base(rec.Path)
+		if reason := want[name]; rec.SkipReason != reason {
+			t.Errorf("%s skipped as %q, want %q", name, rec.SkipReason, reason)
+		}
+		if _, skipped := want[name]; skipped && h.ReadFile(filepath.Join(h.TmpDir(), name)) != files[name] {
+			t.Errorf("skipped file %s was modified", name)
+		}
+	}
+	for _, reason := range []string{skipBinary, skipLFSPointer, skipTooLarge, skipTooLong} {
+		if p.stats.Get(reason) != 1 {
+			t.Errorf("stats[%q] = %d, want 1", reason, p.stats.Get(reason))
+		}
+	}
+	if p.stats.Get(skipMinified) != 2 || p.stats.Get("added") != 2 {
+		t.Errorf("Unexpected stats: %v", p.stats.Snapshot())
+	}
+}
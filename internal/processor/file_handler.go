@@ -1,12 +1,18 @@
 package processor
 
 import (
+	"fmt"
 	"io/fs"
+	"math/rand"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 
+	"github.com/jeeftor/license-manager/internal/charset"
 	"github.com/jeeftor/license-manager/internal/errors"
+	"github.com/jeeftor/license-manager/internal/ignore"
+	"github.com/jeeftor/license-manager/internal/license"
 	"github.com/jeeftor/license-manager/internal/logger"
 	"github.com/jeeftor/license-manager/internal/styles"
 
@@ -17,12 +23,22 @@ import (
 type FileHandler struct {
 	logger *logger.Logger
 	skip   string
+	ignore *ignore.Matcher // .gitignore and .licenseignore files, nil disables them
+
+	attributes *ignore.Attributes // .gitattributes files, nil disables them
+
+	encodings []EncodingRule // Declared encodings, the first matching pattern wins
+	detected  *sync.Map      // Path to the charset.Encoding it was read with
+
+	maxSize  int64 // Files larger than this are skipped, 0 for no limit
+	maxLines int   // Files with more lines are skipped, 0 for no limit
 }
 
 // NewFileHandler creates a new FileHandler
 func NewFileHandler(logger *logger.Logger) *FileHandler {
 	return &FileHandler{
-		logger: logger,
+		logger:   logger,
+		detected: &sync.Map{},
 	}
 }
 
@@ -31,29 +47,71 @@ func (fh *FileHandler) SetSkipPattern(pattern string) {
 	fh.skip = pattern
 }
 
+// SetIgnore sets the matcher for ignore files, nil disables them
+func (fh *FileHandler) SetIgnore(m *ignore.Matcher) {
+	fh.ignore = m
+}
+
+// SetAttributes sets the .gitattributes reader, nil disables it
+func (fh *FileHandler) SetAttributes(a *ignore.Attributes) {
+	fh.attributes = a
+}
+
+// SetEncodings sets the declared encodings of files
+func (fh *FileHandler) SetEncodings(rules []EncodingRule) {
+	fh.encodings = rules
+}
+
+// declaredEncoding returns the encoding declared for a file, or ""
+func (fh *FileHandler) declaredEncoding(path string) string {
+	for _, rule := range fh.encodings {
+		if matched, _ := fh.matchesAny([]string{rule.Pattern}, path); matched {
+			return rule.Encoding
+		}
+	}
+	return ""
+}
+
+// Exclusion returns why .gitattributes excludes a file from licensing
+// (ignore.Generated, ignore.Vendored or ignore.Binary), or "" when it does not
+func (fh *FileHandler) Exclusion(path string) string {
+	if fh.attributes == nil {
+		return ""
+	}
+	return fh.attributes.Exclusion(path)
+}
+
+// excluded checks a path against the skip patterns and ignore files
+func (fh *FileHandler) excluded(path string, isDir bool) bool {
+	if fh.shouldSkip(path) {
+		return true
+	}
+	if fh.ignore != nil && fh.ignore.Ignored(path, isDir) {
+		fh.logger.LogDebug("Path %s is excluded by an ignore file", relativePath(path))
+		return true
+	}
+	return false
+}
+
 // shouldSkip checks if a file should be skipped based on skip patterns
 func (fh *FileHandler) shouldSkip(path string) bool {
 	if fh.skip == "" {
 		return false
 	}
 
-	// Get current working directory
-	cwd, err := os.Getwd()
-	if err != nil {
-		fh.logger.LogError("Error getting working directory: %v", err)
-		return false
+	matched, pattern := fh.matchesAny(strings.Split(fh.skip, ","), path)
+	if matched {
+		fh.logger.LogDebug("Path %s matched skip pattern %s", relativePath(path), pattern)
 	}
+	return matched
+}
 
-	// Convert absolute path to relative path for matching
-	normalizedPath := filepath.ToSlash(path)
-	if strings.HasPrefix(normalizedPath, cwd) {
-		normalizedPath = normalizedPath[len(cwd)+1:]
-	}
-	if strings.HasPrefix(normalizedPath, "./") {
-		normalizedPath = normalizedPath[2:]
-	}
+// matchesAny checks a path against a list of patterns and returns the first pattern that matched.
+// Patterns without wildcards match the file they name, or everything below the directory.
+func (fh *FileHandler) matchesAny(patterns []string, path string) (bool, string) {
+	normalizedPath := relativePath(path)
 
-	for _, pattern := range strings.Split(fh.skip, ",") {
+	for _, pattern := range patterns {
 		pattern = strings.TrimSpace(pattern)
 		if pattern == "" {
 			continue
@@ -65,22 +123,49 @@ func (fh *FileHandler) shouldSkip(path string) bool {
 			pattern = pattern[2:]
 		}
 
-		// Make sure the pattern ends with /** if it's a directory pattern
+		// A pattern without wildcards names a file, or a directory and everything below it
+		candidates := []string{pattern}
 		if !strings.Contains(pattern, "*") {
-			pattern = strings.TrimSuffix(pattern, "/") + "/**"
+			candidates = append(candidates, strings.TrimSuffix(pattern, "/")+"/**")
 		}
 
-		matched, err := doublestar.Match(pattern, normalizedPath)
-		if err != nil {
-			fh.logger.LogError("Error matching skip pattern %s: %v", pattern, err)
-			continue
+		// Absolute patterns, such as paths from a config file, match the absolute path
+		target := normalizedPath
+		if filepath.IsAbs(filepath.FromSlash(pattern)) {
+			if abs, err := filepath.Abs(path); err == nil {
+				target = filepath.ToSlash(abs)
+			}
 		}
-		if matched {
-			fh.logger.LogDebug("Path %s matched skip pattern %s", normalizedPath, pattern)
-			return true
+
+		for _, candidate := range candidates {
+			matched, err := doublestar.Match(candidate, target)
+			if err != nil {
+				fh.logger.LogError("Error matching pattern %s: %v", candidate, err)
+				break
+			}
+			if matched {
+				return true, candidate
+			}
 		}
 	}
-	return false
+	return false, ""
+}
+
+// relativePath converts a path to a slash separated path relative to the working directory
+func relativePath(path string) string {
+	normalizedPath := filepath.ToSlash(path)
+
+	cwd, err := os.Getwd()
+	if err == nil {
+		cwd = filepath.ToSlash(cwd)
+		if strings.HasPrefix(normalizedPath, cwd+"/") {
+			normalizedPath = normalizedPath[len(cwd)+1:]
+		}
+	}
+	if strings.HasPrefix(normalizedPath, "./") {
+		normalizedPath = normalizedPath[2:]
+	}
+	return normalizedPath
 }
 
 // FindFiles finds all files matching the input pattern
@@ -113,7 +198,7 @@ func (fh *FileHandler) FindFiles(pattern string) ([]string, error) {
 			}
 
 			// Check skip pattern BEFORE adding to allFiles
-			if fh.shouldSkip(absPath) {
+			if fh.excluded(absPath, false) {
 				fh.logger.LogDebug("Skipping file: %s", absPath)
 				continue
 			}
@@ -127,56 +212,12 @@ func (fh *FileHandler) FindFiles(pattern string) ([]string, error) {
 		}
 
 		// Handle glob patterns
-		matches, err := doublestar.Glob(p)
+		matches, err := fh.glob(p, cwd)
 		if err != nil {
 			fh.logger.LogError("Invalid glob pattern %s: %v", p, err)
 			continue
 		}
-
-		for _, match := range matches {
-			absMatch := match
-			if !filepath.IsAbs(match) {
-				absMatch = filepath.Join(cwd, match)
-			}
-
-			// Check skip pattern BEFORE processing
-			if fh.shouldSkip(absMatch) {
-				fh.logger.LogDebug("Skipping matched path: %s", absMatch)
-				continue
-			}
-
-			info, err := os.Stat(absMatch)
-			if err != nil {
-				fh.logger.LogError("Error accessing %s: %v", absMatch, err)
-				continue
-			}
-
-			if info.IsDir() {
-				err := filepath.WalkDir(
-					absMatch,
-					func(path string, d fs.DirEntry, err error) error {
-						if err != nil {
-							return nil
-						}
-
-						// Check skip pattern for each walked file
-						if fh.shouldSkip(path) {
-							return filepath.SkipDir
-						}
-
-						if !d.IsDir() && isProcessableFile(path) {
-							allFiles = append(allFiles, path)
-						}
-						return nil
-					},
-				)
-				if err != nil {
-					fh.logger.LogError("Error walking directory %s: %v", absMatch, err)
-				}
-			} else if isProcessableFile(absMatch) {
-				allFiles = append(allFiles, absMatch)
-			}
-		}
+		allFiles = append(allFiles, matches...)
 	}
 
 	// Remove duplicates while preserving order
@@ -196,54 +237,276 @@ func (fh *FileHandler) FindFiles(pattern string) ([]string, error) {
 	return uniqueFiles, nil
 }
 
-// ReadFile reads a file and returns its content
+// glob expands a glob pattern to processable files. Matching directories are
+// expanded to the files below them, and skipped or ignored directories are not
+// descended into.
+func (fh *FileHandler) glob(pattern, cwd string) ([]string, error) {
+	for strings.HasPrefix(pattern, "./") {
+		pattern = pattern[2:]
+	}
+	if _, err := doublestar.Match(pattern, ""); err != nil {
+		return nil, err
+	}
+
+	// Walk from the longest leading path without wildcards
+	segments := strings.Split(pattern, "/")
+	var literal []string
+	for _, segment := range segments[:len(segments)-1] {
+		if strings.ContainsAny(segment, "*?[{") {
+			break
+		}
+		literal = append(literal, segment)
+	}
+	root := strings.Join(literal, "/")
+	if root == "" {
+		root = "."
+		if strings.HasPrefix(pattern, "/") {
+			root = "/"
+		}
+	}
+
+	var files []string
+	absolute := func(path string) string {
+		if filepath.IsAbs(path) {
+			return path
+		}
+		return filepath.Join(cwd, path)
+	}
+	err := filepath.WalkDir(filepath.FromSlash(root), func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if d == nil || path == filepath.FromSlash(root) {
+				return filepath.SkipDir // missing root, no matches
+			}
+			fh.logger.LogError("Error accessing %s: %v", path, err)
+			return nil
+		}
+		if path == filepath.FromSlash(root) {
+			return nil
+		}
+
+		absPath := absolute(path)
+		if fh.excluded(absPath, d.IsDir()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		matched, _ := doublestar.Match(pattern, filepath.ToSlash(path))
+		if d.IsDir() {
+			if matched {
+				files = append(files, fh.walkFiles(absPath)...)
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if matched && isProcessableFile(absPath) {
+			files = append(files, absPath)
+		}
+		return nil
+	})
+	return files, err
+}
+
+// walkFiles returns the processable files below dir that are not skipped or ignored
+func (fh *FileHandler) walkFiles(dir string) []string {
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if path != dir && fh.excluded(path, d.IsDir()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.IsDir() && isProcessableFile(path) {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		fh.logger.LogError("Error walking directory %s: %v", dir, err)
+	}
+	return files
+}
+
+// ReadFile reads a file and returns its content as UTF-8 text. Files in other
+// encodings are converted, files in unknown encodings are refused.
 func (fh *FileHandler) ReadFile(path string) (string, error) {
-	content, err := os.ReadFile(path)
+	data, err := os.ReadFile(path)
 	if err != nil {
 		return "", errors.NewFileError("failed to read file", path, "read")
 	}
-	return string(content), nil
+
+	enc, err := charset.Detect(data, fh.declaredEncoding(path))
+	var text string
+	if err == nil {
+		text, err = enc.Decode(data)
+	}
+	if err != nil {
+		return "", errors.NewFileError("unsupported encoding: "+err.Error(), path, "read")
+	}
+	if enc.Name != charset.UTF8 {
+		fh.logger.LogInfo("  Encoding: %s", enc)
+	}
+	fh.detected.Store(path, enc)
+	return text, nil
 }
 
-// WriteFile writes content to a file
-func (fh *FileHandler) WriteFile(path string, content string) error {
-	err := os.WriteFile(path, []byte(content), 0644)
+// encoding returns the encoding the file at path was read with, UTF-8 for
+// files that were not read
+func (fh *FileHandler) encoding(path string) charset.Encoding {
+	if detected, ok := fh.detected.Load(path); ok {
+		return detected.(charset.Encoding)
+	}
+	return charset.Encoding{Name: charset.UTF8}
+}
+
+// Encode converts text to the encoding the file at path was read with. The
+// license markers are left out in encodings that cannot represent them.
+func (fh *FileHandler) Encode(path, text string) (string, error) {
+	enc := fh.encoding(path)
+	if !enc.Unicode() {
+		text = license.StripMarkers(text)
+	}
+	data, err := enc.Encode(text)
 	if err != nil {
-		return errors.NewFileError("failed to write file", path, "write")
+		return "", errors.NewFileError(err.Error(), path, "write")
 	}
-	return nil
+	return string(data), nil
 }
 
-// BackupFile creates a backup of a file
-func (fh *FileHandler) BackupFile(path string) error {
-	content, err := fh.ReadFile(path)
+// WriteFile replaces a file atomically with content. Existing files keep their
+// mode and ownership, new files get the umask and the group of setgid directories.
+// The rename gives the file a new inode, so hard links to it keep the old content.
+// When the ownership cannot be kept, e.g. in a group writable checkout owned by
+// someone else, the file is overwritten in place instead.
+func (fh *FileHandler) WriteFile(path string, content string) error {
+	// Write through symlinks instead of replacing them
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+
+	info, err := os.Stat(path)
+	if err != nil && !os.IsNotExist(err) {
+		return errors.NewFileError("failed to stat file", path, "write")
+	}
 	if err != nil {
-		return err
+		info = nil
 	}
+	err = writeAtomic(path, content, info, true)
+	if err == errOwnership {
+		fh.logger.LogWarning("Cannot keep the owner of %s, writing it in place", relativePath(path))
+		return writeInPlace(path, content)
+	}
+	return err
+}
 
-	backupPath := path + ".bak"
-	err = fh.WriteFile(backupPath, content)
+// WriteFileTo writes content to dst with the permissions of src, creating the
+// parent directories of dst
+func (fh *FileHandler) WriteFileTo(src, dst string, content string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return errors.NewFileError("failed to create output directory", filepath.Dir(dst), "write")
+	}
+	info, err := os.Stat(src)
 	if err != nil {
-		return errors.NewFileError("failed to create backup", path, "backup")
+		info = nil
 	}
+	return writeAtomic(dst, content, info, false)
+}
 
-	return nil
+// ReadOnly reports whether a file exists and nobody may write it
+func (fh *FileHandler) ReadOnly(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Mode().Perm()&0222 == 0
 }
 
-// RestoreFile restores a file from its backup
-func (fh *FileHandler) RestoreFile(path string) error {
-	backupPath := path + ".bak"
-	content, err := fh.ReadFile(backupPath)
+// errOwnership is returned by writeAtomic when the temporary file cannot be
+// given the owner of the file it replaces
+var errOwnership = errors.NewFileError("failed to keep file ownership", "", "write")
+
+// writeAtomic writes content to a temporary file next to path, syncs it and
+// renames it over path, so readers never see a partly written file. The mode
+// of like is applied, and its owner when chown is set; without like the file
+// is created with the defaults of the directory.
+func writeAtomic(path, content string, like os.FileInfo, chown bool) error {
+	tmp, err := createTemp(path)
 	if err != nil {
-		return err
+		return errors.NewFileError("failed to create temporary file", path, "write")
 	}
+	name := tmp.Name()
 
-	err = fh.WriteFile(path, content)
+	err = func() error {
+		defer tmp.Close()
+		if _, err := tmp.WriteString(content); err != nil {
+			return errors.NewFileError("failed to write file", path, "write")
+		}
+		if like != nil {
+			mode := like.Mode() & (fs.ModePerm | fs.ModeSetuid | fs.ModeSetgid | fs.ModeSticky)
+			if err := tmp.Chmod(mode); err != nil {
+				return errors.NewFileError("failed to keep file mode", path, "write")
+			}
+			if chown && chownLike(tmp, like) != nil {
+				return errOwnership
+			}
+		}
+		if err := tmp.Sync(); err != nil {
+			return errors.NewFileError("failed to sync file", path, "write")
+		}
+		return nil
+	}()
+	if err == nil && os.Rename(name, path) != nil {
+		err = errors.NewFileError("failed to replace file", path, "write")
+	}
 	if err != nil {
+		os.Remove(name)
 		return err
 	}
 
-	return os.Remove(backupPath)
+	syncDir(filepath.Dir(path))
+	return nil
+}
+
+// writeInPlace truncates and rewrites an existing file, keeping its inode and
+// with it the owner, mode and hard links
+func writeInPlace(path, content string) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_TRUNC, 0)
+	if err != nil {
+		return errors.NewFileError("failed to open file", path, "write")
+	}
+	defer f.Close()
+	if _, err := f.WriteString(content); err != nil {
+		return errors.NewFileError("failed to write file", path, "write")
+	}
+	if err := f.Sync(); err != nil {
+		return errors.NewFileError("failed to sync file", path, "write")
+	}
+	return nil
+}
+
+// createTemp creates an empty hidden file next to path. Unlike os.CreateTemp it
+// uses mode 0666, so the umask applies as it would to path itself.
+func createTemp(path string) (*os.File, error) {
+	dir, base := filepath.Split(path)
+	for i := 0; ; i++ {
+		name := filepath.Join(dir, fmt.Sprintf(".%s.%d.lm-tmp", base, rand.Uint32()))
+		f, err := os.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0666)
+		if os.IsExist(err) && i < 100 {
+			continue
+		}
+		return f, err
+	}
+}
+
+// syncDir flushes a directory so a rename in it survives a crash. Errors are
+// ignored, not every platform can sync directories.
+func syncDir(dir string) {
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
 }
 
 // isProcessableFile checks if a file should be processed based on its extension
@@ -0,0 +1,239 @@
+package processor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jeeftor/license-manager/internal/charset"
+	"github.com/jeeftor/license-manager/internal/force"
+	"github.com/jeeftor/license-manager/internal/license"
+	"github.com/jeeftor/license-manager/internal/report"
+)
+
+func TestAtomicWrites(t *testing.T) {
+	h := NewTestHelper(t, "Copyright (c) 2025 Acme Corp")
+	script := h.CreateFile("run.sh", "#!/bin/sh\necho hi\n")
+	if err := os.Chmod(script, 0750); err != nil {
+		t.Fatal(err)
+	}
+	readOnly := h.CreateFile("frozen.go", "package frozen\n")
+	if err := os.Chmod(readOnly, 0444); err != nil {
+		t.Fatal(err)
+	}
+	target := h.CreateFile("real/target.go", "package target\n")
+	link := filepath.Join(h.TmpDir(), "link.go")
+	if err := os.Symlink(target, link); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	processor := h.CreateProcessor(filepath.Join(h.TmpDir(), "*"), force.No)
+	processor.config.Skip = "LICENSE"
+	if err := processor.Add(); err != nil {
+		t.Fatalf("Add() failed: %v", err)
+	}
+
+	if info, err := os.Stat(script); err != nil || info.Mode().Perm() != 0750 {
+		t.Errorf("script mode = %v, %v, want 0750", info.Mode(), err)
+	}
+	if !strings.Contains(h.ReadFile(script), "Acme Corp") {
+		t.Error("script has no license")
+	}
+
+	if got := h.ReadFile(readOnly); got != "package frozen\n" {
+		t.Errorf("read-only file was changed: %q", got)
+	}
+	for _, rec := range processor.Records() {
+		if filepath.Base(rec.Path) == "frozen.go" && (rec.Action != report.ActionSkipped || rec.SkipReason != skipReadOnly) {
+			t.Errorf("read-only file recorded as %s (%s)", rec.Action, rec.SkipReason)
+		}
+	}
+
+	if info, err := os.Lstat(link); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Errorf("symlink was replaced: %v, %v", info.Mode(), err)
+	}
+	if !strings.Contains(h.ReadFile(target), "Acme Corp") {
+		t.Error("symlink target has no license")
+	}
+
+	entries, err := os.ReadDir(h.TmpDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		if strings.HasSuffix(entry.Name(), ".lm-tmp") {
+			t.Errorf("temporary file %s was left behind", entry.Name())
+		}
+	}
+}
+
+func TestWriteInPlace(t *testing.T) {
+	h := NewTestHelper(t, "Copyright (c) 2025 Acme Corp")
+	file := h.CreateFile("main.go", "package main\n\nfunc main() {}\n")
+	link := filepath.Join(h.TmpDir(), "linked.go")
+	if err := os.Link(file, link); err != nil {
+		t.Skipf("hard links not supported: %v", err)
+	}
+
+	if err := writeInPlace(file, "package main\n"); err != nil {
+		t.Fatalf("writeInPlace() failed: %v", err)
+	}
+	if got := h.ReadFile(link); got != "package main\n" {
+		t.Errorf("hard link reads %q, want the new content", got)
+	}
+	if err := writeInPlace(filepath.Join(h.TmpDir(), "missing.go"), "package main\n"); err == nil {
+		t.Error("writeInPlace() should not create missing files")
+	}
+}
+
+func TestLineEndingsAndBOM(t *testing.T) {
+	h := NewTestHelper(t, "Copyright (c) 2025 Acme Corp\nAll rights reserved.")
+	files := map[string]string{
+		"crlf.go":  "package main\r\n\r\nfunc main() {}\r\n",
+		"bom.xml":  "\uFEFF<?xml version=\"1.0\"?>\r\n<root/>\r\n",
+		"bom.sh":   "\uFEFF#!/bin/sh\necho hi\n",
+		"mixed.py": "import os\r\nprint(os.name)\n",
+	}
+	for name, content := range files {
+		h.CreateFile(name, content)
+	}
+
+	processor := h.CreateProcessor(filepath.Join(h.TmpDir(), "*.*"), force.No)
+	processor.config.Skip = "LICENSE"
+	if err := processor.Add(); err != nil {
+		t.Fatalf("Add() failed: %v", err)
+	}
+	for name, original := range files {
+		content := h.ReadFile(filepath.Join(h.TmpDir(), name))
+		if !strings.Contains(content, "Acme Corp") {
+			t.Errorf("%s has no license", name)
+		}
+		_, format := license.NormalizeText(original)
+		if format.CRLF && strings.Count(content, "\r\n") != strings.Count(content, "\n") {
+			t.Errorf("%s has mixed line endings after Add():\n%q", name, content)
+		}
+		if format.BOM && !strings.HasPrefix(content, "\uFEFF") {
+			t.Errorf("%s lost its byte order mark: %q", name, content)
+		}
+		if strings.Count(content, "\uFEFF") > 1 {
+			t.Errorf("%s has more than one byte order mark: %q", name, content)
+		}
+	}
+
+	if err := processor.Check(); err != nil {
+		t.Fatalf("Check() failed after Add(): %v", err)
+	}
+	if err := processor.Remove(); err != nil {
+		t.Fatalf("Remove() failed: %v", err)
+	}
+	for name, original := range files {
+		if got := h.ReadFile(filepath.Join(h.TmpDir(), name)); got != original {
+			t.Errorf("%s = %q after Remove(), want %q", name, got, original)
+		}
+	}
+}
+
+func TestEncodings(t *testing.T) {
+	h := NewTestHelper(t, "Copyright (c) 2025 Acme Corp\nAll rights reserved.")
+	utf16 := "\xff\xfe" + string([]byte{'p', 0, 'a', 0, 'c', 0, 'k', 0, 'a', 0, 'g', 0, 'e', 0, ' ', 0, 'm', 0, '\n', 0})
+	files := map[string]string{
+		"utf16.go":       utf16,
+		"legacy/latin.c": "int caf\xe9 = 1;\n",
+		"unknown.c":      "int caf\xe9 = 1;\n",
+		"single.c":       "int na\xefve = 1;\n",
+	}
+	for name, content := range files {
+		h.CreateFile(name, content)
+	}
+
+	processor := h.CreateProcessor(filepath.Join(h.TmpDir(), "**", "*.*"), force.No)
+	processor.config.Skip = "LICENSE"
+	processor.fileHandler.SetEncodings([]EncodingRule{
+		{Pattern: "**/legacy/*.c", Encoding: charset.Latin1},
+		{Pattern: filepath.Join(h.TmpDir(), "single.c"), Encoding: charset.Latin1}, // a single file
+	})
+	_ = processor.Add()
+
+	for name, enc := range map[string]charset.Encoding{
+		"utf16.go":       {Name: charset.UTF16LE, BOM: true},
+		"legacy/latin.c": {Name: charset.Latin1},
+		"single.c":       {Name: charset.Latin1},
+	} {
+		data := h.ReadFile(filepath.Join(h.TmpDir(), name))
+		text, err := enc.Decode([]byte(data))
+		if err != nil {
+			t.Fatalf("%s is no longer %s: %v", name, enc, err)
+		}
+		if !strings.Contains(text, "Acme Corp") {
+			t.Errorf("%s has no license:\n%s", name, text)
+		}
+	}
+	if !strings.Contains(h.ReadFile(filepath.Join(h.TmpDir(), "legacy", "latin.c")), "caf\xe9") {
+		t.Error("legacy/latin.c was not written back as Latin-1")
+	}
+	if got := h.ReadFile(filepath.Join(h.TmpDir(), "unknown.c")); got != files["unknown.c"] {
+		t.Errorf("unknown.c in an undeclared encoding was modified: %q", got)
+	}
+
+	// Headers are found again in files that cannot hold the markers
+	os.Remove(filepath.Join(h.TmpDir(), "unknown.c"))
+	if err := processor.Check(); err != nil {
+		t.Fatalf("Check() failed after Add(): %v", err)
+	}
+	if err := processor.Remove(); err != nil {
+		t.Fatalf("Remove() failed: %v", err)
+	}
+	for name, original := range files {
+		if name == "unknown.c" {
+			continue
+		}
+		if got := h.ReadFile(filepath.Join(h.TmpDir(), name)); got != original {
+			t.Errorf("%s = %q after Remove(), want %q", name, got, original)
+		}
+	}
+}
+
+// TestEncodedBanner verifies that comment banners drawn like a header style are
+// not taken for a license in files that cannot hold the markers
+func TestEncodedBanner(t *testing.T) {
+	h := NewTestHelper(t, "Copyright (c) 2025 Acme Corp\nAll rights reserved.")
+	banner := "/*\n * ----------------------------------------\n * Module: caf\xe9 parser\n" +
+		" * ----------------------------------------\n */\nint x;\n"
+	file := h.CreateFile("banner.c", banner)
+
+	run := func(op func(*FileProcessor) error) *FileProcessor {
+		processor := h.CreateProcessor(file, force.No)
+		processor.config.PresetStyle = "simple"
+		processor.fileHandler.SetEncodings([]EncodingRule{{Pattern: "**/*.c", Encoding: charset.Latin1}})
+		if err := op(processor); err != nil {
+			t.Fatalf("operation failed: %v", err)
+		}
+		return processor
+	}
+
+	run((*FileProcessor).Remove)
+	if got := h.ReadFile(file); got != banner {
+		t.Errorf("Remove() changed the banner: %q", got)
+	}
+	run((*FileProcessor).Update)
+	if got := h.ReadFile(file); got != banner {
+		t.Errorf("Update() changed the banner: %q", got)
+	}
+
+	processor := run((*FileProcessor).Add)
+	if recs := processor.Records(); len(recs) != 1 || recs[0].Action != report.ActionAdded {
+		t.Errorf("Add() records = %+v, want the license added", recs)
+	}
+	got := h.ReadFile(file)
+	if !strings.HasSuffix(got, banner) || !strings.Contains(got, "Acme Corp") {
+		t.Errorf("Add() wrote %q, want the license before the banner", got)
+	}
+
+	// The license, not the banner, is found and removed again
+	run((*FileProcessor).Check)
+	run((*FileProcessor).Remove)
+	if got := h.ReadFile(file); got != banner {
+		t.Errorf("Remove() after Add() = %q, want %q", got, banner)
+	}
+}
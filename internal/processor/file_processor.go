@@ -2,15 +2,23 @@ package processor
 
 import (
 	"fmt"
+	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/jeeftor/license-manager/internal/language"
 
 	"github.com/fatih/color"
+	"github.com/jeeftor/license-manager/internal/errors"
 	"github.com/jeeftor/license-manager/internal/force"
+	"github.com/jeeftor/license-manager/internal/git"
+	"github.com/jeeftor/license-manager/internal/ignore"
+	"github.com/jeeftor/license-manager/internal/journal"
 	"github.com/jeeftor/license-manager/internal/license"
 	"github.com/jeeftor/license-manager/internal/logger"
+	"github.com/jeeftor/license-manager/internal/report"
 	"github.com/jeeftor/license-manager/internal/styles"
 )
 
@@ -19,37 +27,118 @@ type FileProcessor struct {
 	config      *Config
 	fileHandler *FileHandler
 	logger      *logger.Logger
-	stats       map[string]int
+	stats       *Stats
+	repo        *git.Repo        // set when the year policy reads git history
+	journal     *journal.Journal // undo journal of the running operation, nil when disabled
+	records     []report.Record  // per-file results of the last operation
 }
 
 // NewFileProcessor creates a new FileProcessor instance
 func NewFileProcessor(cfg *Config) *FileProcessor {
 	log := logger.NewLogger(cfg.LogLevel)
+	if cfg.LogOutput != nil {
+		log.SetOutput(cfg.LogOutput)
+	}
 	fh := NewFileHandler(log)
-	fh.SetSkipPattern(cfg.Skip) // Set the skip pattern
+	skip := cfg.Skip
+	for _, dir := range []string{dirSkip(cfg.OutputDir), dirSkip(cfg.StateDir)} {
+		if dir != "" {
+			skip = strings.TrimPrefix(skip+","+dir, ",")
+		}
+	}
+	fh.SetSkipPattern(skip) // Set the skip pattern
+	// Outside a repository ignore files are read up to the config file or working directory
+	root := cfg.RuleDir
+	if root == "" {
+		root, _ = os.Getwd()
+	}
+	fh.SetIgnore(ignore.New(!cfg.NoGitignore, root))
+	fh.SetEncodings(cfg.Encodings)
+	fh.SetLimits(cfg.MaxFileSize, cfg.MaxLines)
+	if !cfg.NoGitattributes {
+		fh.SetAttributes(ignore.NewAttributes(root))
+	}
 	return &FileProcessor{
 		config:      cfg,
 		logger:      log,
 		fileHandler: fh,
-		stats:       make(map[string]int),
+		stats:       NewStats(),
 	}
 }
 
+// licenseSettings holds the license configuration that applies to a single file
+type licenseSettings struct {
+	LicenseText       string
+	PresetStyle       string
+	ForceCommentStyle force.ForceCommentStyle
+}
+
+// settingsFor resolves the license settings for a file, applying the first matching rule
+func (fp *FileProcessor) settingsFor(file string) licenseSettings {
+	settings := licenseSettings{
+		LicenseText:       fp.config.LicenseText,
+		PresetStyle:       fp.config.PresetStyle,
+		ForceCommentStyle: fp.config.ForceCommentStyle,
+	}
+
+	path := fp.rulePath(file)
+	for i, rule := range fp.config.Rules {
+		matched, pattern := fp.fileHandler.matchesAny(rule.Patterns, path)
+		if !matched {
+			continue
+		}
+
+		fp.logger.LogInfo("  Using rule %d (matched %s)", i+1, pattern)
+		if rule.LicenseText != "" {
+			settings.LicenseText = rule.LicenseText
+		}
+		if rule.PresetStyle != "" {
+			settings.PresetStyle = rule.PresetStyle
+		}
+		if rule.ForceCommentStyle != "" { // an explicit "no" turns off the global setting
+			settings.ForceCommentStyle = rule.ForceCommentStyle
+		}
+		break
+	}
+
+	return settings
+}
+
+// rulePath returns the path rule patterns are matched against: relative to the
+// rule directory, so rules match the same files from any working directory
+func (fp *FileProcessor) rulePath(file string) string {
+	if fp.config.RuleDir == "" {
+		return file
+	}
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return file
+	}
+	rel, err := filepath.Rel(fp.config.RuleDir, abs)
+	rel = filepath.ToSlash(rel)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, "../") {
+		return file
+	}
+	return rel
+}
+
 func (fp *FileProcessor) createLicenseManager(
 	file string,
 ) (*license.LicenseManager, styles.CommentLanguage, error) {
+	settings := fp.settingsFor(file)
+
 	// Get comment headerFooterStyle for file type
 	ext := filepath.Ext(file)
 	commentStyle := styles.GetLanguageCommentStyle(ext)
 
-	if fp.config.ForceCommentStyle == force.Single {
+	if settings.ForceCommentStyle == force.Single {
 		fp.logger.LogWarning(
 			"Overriding default comment headerFooterStyle to %s",
-			fp.config.ForceCommentStyle,
+			settings.ForceCommentStyle,
 		)
 		commentStyle.PreferMulti = false
-	} else if fp.config.ForceCommentStyle == force.Multi {
-		fp.logger.LogWarning("Overriding default comment headerFooterStyle to %s", fp.config.ForceCommentStyle)
+	} else if settings.ForceCommentStyle == force.Multi {
+		fp.logger.LogWarning("Overriding default comment headerFooterStyle to %s", settings.ForceCommentStyle)
 		commentStyle.PreferMulti = true
 	}
 
@@ -63,51 +152,119 @@ func (fp *FileProcessor) createLicenseManager(
 		return nil, commentStyle, err
 	}
 
+	// Render the license template for this file
+	data, err := fp.templateData(file, commentStyle)
+	if err != nil {
+		return nil, commentStyle, err
+	}
+	licenseText, err := license.RenderTemplate(settings.LicenseText, data)
+	if err != nil {
+		return nil, commentStyle, err
+	}
+
 	// Create single manager with the actual license text
 	lm := license.NewLicenseManager(
 		fp.logger,
-		fp.config.LicenseText,
+		licenseText,
 		ext,
-		styles.Get(fp.config.PresetStyle),
+		styles.Get(settings.PresetStyle),
 		commentStyle,
 	)
+	usesYear := license.UsesYear(settings.LicenseText)
+	lm.SetYearTolerant(usesYear && fp.yearPolicy() == license.YearAny)
+	lm.SetYearChecked(usesYear && fp.yearPolicy() != license.YearAny)
 
 	// Set License Mangaer content
 	lm.SetFileContent(content)
+	if !fp.fileHandler.encoding(file).Unicode() {
+		lm.RestoreMarkers() // the encoding cannot hold them, see FileHandler.Encode
+	}
+	if format := lm.TextFormat(); format != (license.TextFormat{}) {
+		fp.logger.LogInfo("  Text format: %s", format)
+	}
 
 	// Scan for license stuff
-	analysis := lm.SearchForLicense(content)
+	analysis := lm.SearchForLicense(lm.FileContent)
 
 	if analysis.HasLicense && analysis.IsStyleMatch {
 		// Update style if none was explicitly configured
-		if fp.config.PresetStyle == "" {
+		if settings.PresetStyle == "" {
 			lm.SetHeaderStyle(analysis.Style)
 			fp.logger.LogInfo("  Using detected style: %s", analysis.Style.Name)
 		} else {
-			fp.logger.LogInfo("  Using configured style: %s", fp.config.PresetStyle)
+			fp.logger.LogInfo("  Using configured style: %s", settings.PresetStyle)
 		}
 	} else {
-		fp.logger.LogInfo("  Using configured style: %s", fp.config.PresetStyle)
+		fp.logger.LogInfo("  Using configured style: %s", settings.PresetStyle)
 	}
 
 	return lm, commentStyle, nil
 }
 
+// yearPolicy returns the configured year policy, license.YearAny by default
+func (fp *FileProcessor) yearPolicy() string {
+	if fp.config.YearPolicy == "" {
+		return license.YearAny
+	}
+	return fp.config.YearPolicy
+}
+
+// usesCommitYears reports whether the year policy reads git history
+func (fp *FileProcessor) usesCommitYears() bool {
+	policy := fp.yearPolicy()
+	return policy == license.YearFirstCommit || policy == license.YearCommitRange
+}
+
+// templateData builds the license template values for a file
+func (fp *FileProcessor) templateData(
+	file string,
+	commentStyle styles.CommentLanguage,
+) (license.TemplateData, error) {
+	var first, last int
+	if fp.usesCommitYears() {
+		err := fp.openRepo() // already open when called from an operation
+		if err == nil {
+			first, last, err = fp.repo.CommitYears(file)
+		}
+		if err != nil {
+			return license.TemplateData{}, err
+		}
+	}
+	year := license.PolicyYear(fp.yearPolicy(), fp.config.Year, first, last)
+
+	return license.TemplateData{
+		Year:     year,
+		Holder:   fp.config.Holder,
+		RelPath:  relativePath(file),
+		FileName: filepath.Base(file),
+		Language: commentStyle.Language,
+		Vars:     fp.config.TemplateVars,
+	}, nil
+}
+
+// requireLicenseText returns an error when no license text applies to the manager's file
+func requireLicenseText(manager *license.LicenseManager, file string) error {
+	if manager.GetLicenseTemplate() == "" {
+		return errors.NewValidationError("no license text configured for "+file, "LicenseText")
+	}
+	return nil
+}
+
 // resetStats resets the operation statistics
 func (fp *FileProcessor) resetStats() {
-	fp.stats = map[string]int{
-		"added":                  0,
-		"existing":               0,
-		"skipped":                0,
-		"failed":                 0,
-		"unchanged":              0,
-		"ok":                     0,
-		"missing":                0,
-		"mismatch":               0,
-		"style_mismatch":         0,
-		"content_style_mismatch": 0,
-		"error":                  0,
-	}
+	fp.stats.Reset(
+		"added",
+		"existing",
+		"skipped",
+		"failed",
+		"unchanged",
+		"ok",
+		"missing",
+		"mismatch",
+		"style_mismatch",
+		"content_style_mismatch",
+		"error",
+	)
 }
 
 // describeCommentStyle returns a human-readable description of the comment style
@@ -156,16 +313,32 @@ func (fp *FileProcessor) logInputPatterns(fileCount int) {
 	}
 }
 
+// skipReadOnly is the skip reason of files without write permission
+const skipReadOnly = "read-only"
+
+// writable reports whether a file can be changed. Read-only files are not
+// replaced in place, they are recorded as skipped instead.
+func (fp *FileProcessor) writable(rec *report.Record, file string) bool {
+	if fp.config.PatchFile != "" || fp.config.OutputDir != "" || !fp.fileHandler.ReadOnly(file) {
+		return true
+	}
+	fp.stats.Inc(skipReadOnly)
+	fp.logger.LogWarning("Skipping read-only file %s", file)
+	rec.Action = report.ActionSkipped
+	rec.SkipReason = skipReadOnly
+	return false
+}
+
 // confirmAction checks if an action should proceed based on dry-run and prompt settings
 func (fp *FileProcessor) confirmAction(action, file string) bool {
 	if fp.config.DryRun {
-		fp.logger.LogInfo("Would %s license in %s", action, file)
+		fp.logger.LogNotice("Would %s license in %s", action, file)
 		return false
 	}
 
 	if fp.config.Prompt && !fp.logger.Prompt(
 		fp.logger.LogQuestion("%s license in %s?", action, file)) {
-		fp.stats["skipped"]++
+		fp.stats.Inc("skipped")
 		fp.logger.LogInfo("Skipping %s", file)
 		return false
 	}
@@ -175,7 +348,7 @@ func (fp *FileProcessor) confirmAction(action, file string) bool {
 
 // handleFileError logs file errors and updates stats
 func (fp *FileProcessor) handleFileError(file, operation string, err error) bool {
-	fp.stats["failed"]++
+	fp.stats.Inc("failed")
 	fp.logger.LogError("Failed to %s %s: %v", operation, file, err)
 	return false
 }
@@ -183,11 +356,25 @@ func (fp *FileProcessor) handleFileError(file, operation string, err error) bool
 // PrepareOperation sets up common operation requirements
 func (fp *FileProcessor) PrepareOperation() ([]string, error) {
 	fp.resetStats()
+	fp.records = nil
+
+	if fp.usesCommitYears() {
+		if err := fp.openRepo(); err != nil {
+			return nil, err
+		}
+	} else if fp.config.recordsDiffs() {
+		_ = fp.openRepo() // diff paths are relative to the repository root, if there is one
+	}
 
 	files, err := fp.fileHandler.FindFiles(fp.config.Input)
 	if err != nil {
 		return nil, err
 	}
+	if fp.config.usesGit() {
+		if files, err = fp.selectGitFiles(files); err != nil {
+			return nil, err
+		}
+	}
 
 	fp.logInputPatterns(len(files))
 	return files, nil
@@ -209,58 +396,133 @@ func (fp *FileProcessor) GetFileComponents(
 	return manager, manager.InitialComponents, nil
 }
 
+// newRecord starts the report record for a file
+func newRecord(
+	file string,
+	manager *license.LicenseManager,
+	commentStyle styles.CommentLanguage,
+) report.Record {
+	rec := report.Record{
+		Path:     relativePath(file),
+		Language: commentStyle.Language,
+	}
+	if manager != nil {
+		rec.Handler = manager.HandlerName()
+		rec.Style = manager.DetectedStyle
+		rec.StartLine, rec.EndLine = manager.HeaderLines()
+	}
+	return rec
+}
+
+// failRecord logs a file error and marks the record as failed
+func (fp *FileProcessor) failRecord(rec report.Record, file, operation string, err error) report.Record {
+	fp.handleFileError(file, operation, err)
+	rec.Action = report.ActionFailed
+	rec.Error = err.Error()
+	return rec
+}
+
+// declinedAction returns the action recorded when confirmAction refuses a change
+func (fp *FileProcessor) declinedAction() string {
+	if fp.config.DryRun {
+		return report.ActionDryRun
+	}
+	return report.ActionSkipped
+}
+
+// Records returns the per-file results of the last operation
+func (fp *FileProcessor) Records() []report.Record {
+	return fp.records
+}
+
+// Report returns the records and totals of the last operation
+func (fp *FileProcessor) Report(command string) report.Report {
+	totals := fp.stats.Snapshot()
+	totals["files"] = len(fp.records)
+
+	return report.Report{
+		Command: command,
+		Files:   fp.records,
+		Totals:  totals,
+	}
+}
+
 // Add adds license headers to files
-// In file_processor.go
 func (fp *FileProcessor) Add() error {
 	files, err := fp.PrepareOperation()
 	if err != nil {
 		return err
 	}
+	fp.startJournal("add")
 
-	for _, file := range files {
-		manager, commentStyle, err := fp.createLicenseManager(file)
-		if err != nil {
-			fp.handleFileError(file, "process", err)
-			continue
-		}
+	_, err = fp.processFiles(files, func(fp *FileProcessor, file string) fileResult {
+		return fileResult{record: fp.addFile(file)}
+	})
+	if err != nil {
+		return err
+	}
 
-		// We already have license status from SearchForLicense() in createLicenseManager
-		if manager.HasInitialLicense {
-			fp.stats["existing"]++
-			if !fp.config.IsPreCommit {
-				fp.logger.LogWarning("License already exists in %s", file)
-			}
-			continue
-		}
+	return fp.finishChanges("Add license headers")
+}
 
-		// Use the components we already have instead of re-extracting
-		newContent, err := manager.AddLicense(manager.InitialComponents, commentStyle.Language)
-		if err != nil {
-			fp.handleFileError(file, "add license to", err)
-			continue
-		}
+// addFile adds a license header to a single file
+func (fp *FileProcessor) addFile(file string) report.Record {
+	manager, commentStyle, err := fp.createLicenseManager(file)
+	rec := newRecord(file, manager, commentStyle)
+	if err == nil {
+		err = requireLicenseText(manager, file)
+	}
+	if err != nil {
+		return fp.failRecord(rec, file, "process", err)
+	}
+	if fp.skipPragmaFile(&rec, file, manager) || fp.skipGeneratedFile(&rec, file, manager, commentStyle, true) {
+		return rec
+	}
 
-		// Debug the actual comment being added in verbose mode
-		fp.logger.LogInfo("  License will be added as:")
-		formattedLicense := manager.FormatLicenseForFile(fp.config.LicenseText)
-		for _, line := range strings.Split(formattedLicense, "\n") {
-			fp.logger.LogInfo("    %s", line)
+	// We already have license status from SearchForLicense() in createLicenseManager
+	if manager.HasInitialLicense {
+		fp.stats.Inc("existing")
+		if !fp.config.IsPreCommit {
+			fp.logger.LogWarning("License already exists in %s", file)
 		}
+		rec.Action = report.ActionExisting
+		return rec
+	}
+	rec.Status = license.NoLicense.Name()
 
-		if !fp.confirmAction("add", file) {
-			continue
-		}
+	// Use the components we already have instead of re-extracting
+	newContent, err := manager.AddLicense(manager.InitialComponents, commentStyle.Language)
+	if err != nil {
+		return fp.failRecord(rec, file, "add license to", err)
+	}
 
-		if err := fp.fileHandler.WriteFile(file, newContent); err != nil {
-			fp.handleFileError(file, "write", err)
-			continue
-		}
+	// Debug the actual comment being added in verbose mode
+	fp.logger.LogInfo("  License will be added as:")
+	formattedLicense := manager.FormatLicenseForFile(manager.GetLicenseTemplate())
+	for _, line := range strings.Split(formattedLicense, "\n") {
+		fp.logger.LogInfo("    %s", line)
+	}
 
-		fp.stats["added"]++
-		fp.logger.LogSuccess("Added license to %s", file)
+	if err := fp.showDiff(&rec, file, manager.Restore(manager.FileContent), manager.Restore(newContent)); err != nil {
+		return fp.failRecord(rec, file, "diff", err)
 	}
 
-	return nil
+	if !fp.writable(&rec, file) {
+		return rec
+	}
+
+	if !fp.confirmAction("add", file) {
+		rec.Action = fp.declinedAction()
+		return rec
+	}
+
+	if err := fp.writeFile(file, manager.Restore(newContent)); err != nil {
+		return fp.failRecord(rec, file, "write", err)
+	}
+
+	fp.logWritten(file, "added", "Added license to %s", file)
+	rec.Action = report.ActionAdded
+	return rec
 }
 
 // Update updates license headers in files
@@ -269,97 +531,245 @@ func (fp *FileProcessor) Update() error {
 	if err != nil {
 		return err
 	}
+	fp.startJournal("update")
 
-	for _, file := range files {
-		manager, commentStyle, err := fp.createLicenseManager(file)
-		if err != nil {
-			fp.handleFileError(file, "process", err)
-			continue
-		}
+	_, err = fp.processFiles(files, func(fp *FileProcessor, file string) fileResult {
+		return fileResult{record: fp.updateFile(file)}
+	})
+	if err != nil {
+		return err
+	}
+	if err := fp.finishChanges("Update license headers"); err != nil {
+		return err
+	}
 
-		status := manager.CheckLicenseStatus(manager.FileContent)
-		if status == license.NoLicense {
-			fp.stats["skipped"]++
-			fp.logger.LogInfo("Skipping %s (no license)", file)
-			continue
-		}
+	fp.logger.PrintStats(fp.stats.Snapshot(), "Updated")
+	return nil
+}
 
-		if status == license.FullMatch {
-			fp.stats["unchanged"]++
-			fp.logger.LogInfo("License is up-to-date in %s", file)
-			continue
-		}
+// updateFile replaces an outdated license header in a single file
+func (fp *FileProcessor) updateFile(file string) report.Record {
+	manager, commentStyle, err := fp.createLicenseManager(file)
+	rec := newRecord(file, manager, commentStyle)
+	if err == nil {
+		err = requireLicenseText(manager, file)
+	}
+	if err != nil {
+		return fp.failRecord(rec, file, "process", err)
+	}
+	if fp.skipPragmaFile(&rec, file, manager) || fp.skipGeneratedFile(&rec, file, manager, commentStyle, true) {
+		return rec
+	}
+
+	status := manager.CheckLicenseStatus(manager.FileContent)
+	rec.Status = status.Name()
+	if status == license.NoLicense {
+		fp.stats.Inc("skipped")
+		fp.logger.LogInfo("Skipping %s (no license)", file)
+		rec.Action = report.ActionSkipped
+		return rec
+	}
+
+	if status == license.FullMatch {
+		fp.stats.Inc("unchanged")
+		fp.logger.LogInfo("License is up-to-date in %s", file)
+		rec.Action = report.ActionUnchanged
+		return rec
+	}
 
-		newContent, err := manager.UpdateLicense(manager.InitialComponents, commentStyle.Language)
+	var newContent string
+	what := "license"
+	if status == license.OutdatedYear {
+		// Only touch the years, keeping the header as it is written
+		if newContent, err = manager.UpdateYears(); err != nil {
+			fp.logger.LogInfo("  Replacing the whole header: %v", err)
+		} else {
+			what = "copyright year"
+		}
+	}
+	if newContent == "" {
+		newContent, err = manager.UpdateLicense(manager.InitialComponents, commentStyle.Language)
 		if err != nil {
-			fp.handleFileError(file, "update license in", err)
-			continue
+			return fp.failRecord(rec, file, "update license in", err)
 		}
+	}
 
-		if !fp.confirmAction("update", file) {
-			continue
-		}
+	if err := fp.showDiff(&rec, file, manager.Restore(manager.FileContent), manager.Restore(newContent)); err != nil {
+		return fp.failRecord(rec, file, "diff", err)
+	}
 
-		if err := fp.fileHandler.WriteFile(file, newContent); err != nil {
-			fp.handleFileError(file, "write", err)
-			continue
+	if !fp.writable(&rec, file) {
+		return rec
+	}
+
+	if !fp.confirmAction("update", file) {
+		rec.Action = fp.declinedAction()
+		return rec
+	}
+
+	if err := fp.writeFile(file, manager.Restore(newContent)); err != nil {
+		return fp.failRecord(rec, file, "write", err)
+	}
+
+	fp.logWritten(file, "updated", "Updated %s in %s", what, file)
+	rec.Action = report.ActionUpdated
+	return rec
+}
+
+// BumpYear extends the copyright years of managed license headers to the
+// configured year, or the current year, without rewriting the headers
+func (fp *FileProcessor) BumpYear() error {
+	year := time.Now().Year()
+	if fp.config.Year != "" {
+		var err error
+		if year, err = strconv.Atoi(fp.config.Year); err != nil || len(fp.config.Year) != 4 {
+			return errors.NewValidationError("year must be a four digit year, got "+fp.config.Year, "Year")
 		}
+	}
 
-		fp.stats["updated"]++
-		fp.logger.LogSuccess("Updated license in %s", file)
+	files, err := fp.PrepareOperation()
+	if err != nil {
+		return err
 	}
+	fp.startJournal("bump-year")
 
-	fp.logger.PrintStats(fp.stats, "Updated")
+	_, err = fp.processFiles(files, func(fp *FileProcessor, file string) fileResult {
+		return fileResult{record: fp.bumpYearFile(file, year)}
+	})
+	if err != nil {
+		return err
+	}
+	if err := fp.finishChanges("Bump copyright years"); err != nil {
+		return err
+	}
+
+	fp.logger.PrintStats(fp.stats.Snapshot(), "Updated")
 	return nil
 }
 
+// bumpYearFile extends the copyright years in the managed header of a single file
+func (fp *FileProcessor) bumpYearFile(file string, year int) report.Record {
+	manager, commentStyle, err := fp.createLicenseManager(file)
+	rec := newRecord(file, manager, commentStyle)
+	if err != nil {
+		return fp.failRecord(rec, file, "process", err)
+	}
+	if fp.skipPragmaFile(&rec, file, manager) || fp.skipGeneratedFile(&rec, file, manager, commentStyle, true) {
+		return rec
+	}
+
+	if !manager.HasInitialLicense {
+		fp.stats.Inc("skipped")
+		fp.logger.LogInfo("Skipping %s (no license)", file)
+		rec.Action = report.ActionSkipped
+		return rec
+	}
+
+	newContent, err := manager.BumpYears(year)
+	if err != nil {
+		return fp.failRecord(rec, file, "bump copyright year in", err)
+	}
+	if newContent == manager.FileContent {
+		fp.stats.Inc("unchanged")
+		fp.logger.LogInfo("Copyright year is up-to-date in %s", file)
+		rec.Action = report.ActionUnchanged
+		return rec
+	}
+	if err := fp.showDiff(&rec, file, manager.Restore(manager.FileContent), manager.Restore(newContent)); err != nil {
+		return fp.failRecord(rec, file, "diff", err)
+	}
+
+	if !fp.writable(&rec, file) {
+		return rec
+	}
+
+	if !fp.confirmAction("update", file) {
+		rec.Action = fp.declinedAction()
+		return rec
+	}
+
+	if err := fp.writeFile(file, manager.Restore(newContent)); err != nil {
+		return fp.failRecord(rec, file, "write", err)
+	}
+
+	fp.logWritten(file, "updated", "Updated copyright year in %s", file)
+	rec.Action = report.ActionUpdated
+	return rec
+}
+
 // Remove removes license headers from files
 func (fp *FileProcessor) Remove() error {
 	files, err := fp.PrepareOperation()
 	if err != nil {
 		return err
 	}
+	fp.startJournal("remove")
 
-	for _, file := range files {
-		manager, commentStyle, err := fp.createLicenseManager(file)
-		if err != nil {
-			fp.handleFileError(file, "process", err)
-			continue
-		}
+	_, err = fp.processFiles(files, func(fp *FileProcessor, file string) fileResult {
+		return fileResult{record: fp.removeFile(file)}
+	})
+	if err != nil {
+		return err
+	}
+	if err := fp.finishChanges("Remove license headers"); err != nil {
+		return err
+	}
 
-		if !manager.HasInitialLicense {
-			fp.stats["skipped"]++
-			fp.logger.LogInfo("No license found in %s", file)
-			continue
-		}
+	fp.logger.PrintStats(fp.stats.Snapshot(), "Removed")
+	return nil
+}
 
-		newContent, err := manager.RemoveLicense(manager.InitialComponents, commentStyle.Language)
-		if err != nil {
-			fp.handleFileError(file, "remove license from", err)
-			continue
-		}
+// removeFile removes the license header from a single file
+func (fp *FileProcessor) removeFile(file string) report.Record {
+	manager, commentStyle, err := fp.createLicenseManager(file)
+	rec := newRecord(file, manager, commentStyle)
+	if err != nil {
+		return fp.failRecord(rec, file, "process", err)
+	}
+	if fp.skipPragmaFile(&rec, file, manager) || fp.skipGeneratedFile(&rec, file, manager, commentStyle, true) {
+		return rec
+	}
 
-		if newContent == manager.FileContent {
-			fp.stats["unchanged"]++
-			fp.logger.LogInfo("No changes needed for %s", file)
-			continue
-		}
+	if !manager.HasInitialLicense {
+		fp.stats.Inc("skipped")
+		fp.logger.LogInfo("No license found in %s", file)
+		rec.Status = license.NoLicense.Name()
+		rec.Action = report.ActionSkipped
+		return rec
+	}
 
-		if !fp.confirmAction("remove", file) {
-			continue
-		}
+	newContent, err := manager.RemoveLicense(manager.InitialComponents, commentStyle.Language)
+	if err != nil {
+		return fp.failRecord(rec, file, "remove license from", err)
+	}
 
-		if err := fp.fileHandler.WriteFile(file, newContent); err != nil {
-			fp.handleFileError(file, "write", err)
-			continue
-		}
+	if newContent == manager.FileContent {
+		fp.stats.Inc("unchanged")
+		fp.logger.LogInfo("No changes needed for %s", file)
+		rec.Action = report.ActionUnchanged
+		return rec
+	}
 
-		fp.stats["removed"]++
-		fp.logger.LogSuccess("Removed license from %s", file)
+	if err := fp.showDiff(&rec, file, manager.Restore(manager.FileContent), manager.Restore(newContent)); err != nil {
+		return fp.failRecord(rec, file, "diff", err)
 	}
 
-	fp.logger.PrintStats(fp.stats, "Removed")
-	return nil
+	if !fp.writable(&rec, file) {
+		return rec
+	}
+
+	if !fp.confirmAction("remove", file) {
+		rec.Action = fp.declinedAction()
+		return rec
+	}
+
+	if err := fp.writeFile(file, manager.Restore(newContent)); err != nil {
+		return fp.failRecord(rec, file, "write", err)
+	}
+
+	fp.logWritten(file, "removed", "Removed license from %s", file)
+	rec.Action = report.ActionRemoved
+	return rec
 }
 
 // Check verifies license headers in files
@@ -372,50 +782,30 @@ func (fp *FileProcessor) Check() error {
 	hasNoLicense := false
 	hasContentMismatch := false
 	hasStyleMismatch := false
+	hasOutdatedYear := false
 
-	for _, file := range files {
-		relPath := file
-		if rel, err := filepath.Rel(".", file); err == nil {
-			relPath = rel
-		}
-
-		manager, _, err := fp.createLicenseManager(file)
-		if err != nil {
-			fp.stats["failed"]++
-			fp.logger.LogError("Failed to process %s: %v", relPath, err)
-			return NewCheckError(license.NoLicense, fmt.Sprintf("failed to process file: %v", err))
-		}
+	results, err := fp.processFiles(files, func(fp *FileProcessor, file string) fileResult {
+		rec, status, err := fp.checkFile(file)
+		return fileResult{record: rec, status: status, err: err}
+	})
+	if err != nil {
+		return err
+	}
 
-		status := manager.CheckLicenseStatus(manager.FileContent)
-		if status != license.FullMatch {
-			fp.stats["failed"]++
-			switch status {
-			case license.NoLicense:
-				hasNoLicense = true
-				fp.stats["missing"]++
-				fp.logger.LogError("%s: Missing license", relPath)
-			case license.ContentMismatch:
-				hasContentMismatch = true
-				fp.logger.LogError("%s: License content mismatch", relPath)
-			case license.StyleMismatch:
-				hasStyleMismatch = true
-				fp.logger.LogError(
-					"%s: License style mismatch (expected %s)",
-					relPath,
-					manager.GetHeaderStyle().Name,
-				)
-			case license.ContentAndStyleMismatch:
-				hasContentMismatch = true
-				hasStyleMismatch = true
-				fp.logger.LogError("%s: License content and style mismatch", relPath)
-			default:
-				fp.logger.LogError("%s: Unknown license error", relPath)
-			}
-			continue
+	for _, res := range results {
+		switch res.status {
+		case license.NoLicense:
+			hasNoLicense = true
+		case license.ContentMismatch:
+			hasContentMismatch = true
+		case license.StyleMismatch:
+			hasStyleMismatch = true
+		case license.ContentAndStyleMismatch:
+			hasContentMismatch = true
+			hasStyleMismatch = true
+		case license.OutdatedYear:
+			hasOutdatedYear = true
 		}
-
-		fp.stats["passed"]++
-		fp.logger.LogSuccess("%s: License OK", relPath)
 	}
 
 	if hasNoLicense {
@@ -442,7 +832,69 @@ func (fp *FileProcessor) Check() error {
 			"license check failed: some files have style mismatches",
 		)
 	}
+	if hasOutdatedYear {
+		return NewCheckError(
+			license.OutdatedYear,
+			"license check failed: some files have outdated copyright years",
+		)
+	}
 
-	fp.logger.PrintStats(fp.stats, "Checked")
+	fp.logger.PrintStats(fp.stats.Snapshot(), "Checked")
 	return nil
 }
+
+// checkFile verifies the license header of a single file.
+// An error is returned when the file cannot be processed at all.
+func (fp *FileProcessor) checkFile(file string) (report.Record, license.Status, error) {
+	relPath := file
+	if rel, err := filepath.Rel(".", file); err == nil {
+		relPath = rel
+	}
+
+	manager, commentStyle, err := fp.createLicenseManager(file)
+	rec := newRecord(file, manager, commentStyle)
+	if err == nil {
+		err = requireLicenseText(manager, file)
+	}
+	if err != nil {
+		fp.stats.Inc("failed")
+		fp.logger.LogError("Failed to process %s: %v", relPath, err)
+		rec.Action = report.ActionFailed
+		rec.Error = err.Error()
+		return rec, license.NoLicense, NewCheckError(license.NoLicense, fmt.Sprintf("failed to process file: %v", err))
+	}
+	if fp.skipPragmaFile(&rec, file, manager) || fp.skipGeneratedFile(&rec, file, manager, commentStyle, false) {
+		return rec, license.FullMatch, nil
+	}
+
+	status := manager.CheckLicenseStatus(manager.FileContent)
+	rec.Status = status.Name()
+	rec.Action = report.ActionChecked
+	if status == license.FullMatch {
+		fp.stats.Inc("passed")
+		fp.logger.LogSuccess("%s: License OK", relPath)
+		return rec, status, nil
+	}
+
+	fp.stats.Inc("failed")
+	switch status {
+	case license.NoLicense:
+		fp.stats.Inc("missing")
+		rec.Reason = "Missing license"
+	case license.ContentMismatch:
+		rec.Reason = "License content mismatch"
+		rec.Diff = manager.ContentDiff()
+	case license.StyleMismatch:
+		rec.Reason = fmt.Sprintf("License style mismatch (expected %s)", manager.GetHeaderStyle().Name)
+	case license.ContentAndStyleMismatch:
+		rec.Reason = "License content and style mismatch"
+		rec.Diff = manager.ContentDiff()
+	case license.OutdatedYear:
+		rec.Reason = "Outdated copyright year"
+		rec.Diff = manager.ContentDiff()
+	default:
+		rec.Reason = "Unknown license error"
+	}
+	fp.logger.LogError("%s: %s", relPath, rec.Reason)
+	return rec, status, nil
+}
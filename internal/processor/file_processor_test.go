@@ -1,13 +1,18 @@
 package processor
 
 import (
+	"bytes"
+	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
+	"sort"
 	"strings"
 	"testing"
 
 	"github.com/jeeftor/license-manager/internal/force"
 	"github.com/jeeftor/license-manager/internal/logger"
+	"github.com/jeeftor/license-manager/internal/report"
 )
 
 // TestAddLicenseToMultipleFiles tests adding licenses to multiple files
@@ -83,8 +88,8 @@ func SubFunc() {}
 	}
 
 	// Verify stats
-	if processor.stats["added"] != 3 {
-		t.Errorf("Expected 3 files to be processed, got %d", processor.stats["added"])
+	if processor.stats.Get("added") != 3 {
+		t.Errorf("Expected 3 files to be processed, got %d", processor.stats.Get("added"))
 	}
 }
 
@@ -342,11 +347,11 @@ func main() {}
 	}
 
 	// Check stats
-	if processor.stats["existing"] != 1 {
-		t.Errorf("Expected 1 file with existing license, got %d", processor.stats["existing"])
+	if processor.stats.Get("existing") != 1 {
+		t.Errorf("Expected 1 file with existing license, got %d", processor.stats.Get("existing"))
 	}
-	if processor.stats["added"] != 0 {
-		t.Errorf("Expected 0 files added, got %d", processor.stats["added"])
+	if processor.stats.Get("added") != 0 {
+		t.Errorf("Expected 0 files added, got %d", processor.stats.Get("added"))
 	}
 }
 
@@ -393,13 +398,13 @@ func TestCheckLicenseOperation(t *testing.T) {
 	_ = processor.Check() // Ignore error for stats checking
 
 	// Verify stats
-	if processor.stats["passed"] < 1 {
-		t.Errorf("Expected at least 1 file with correct license, got %d", processor.stats["passed"])
+	if processor.stats.Get("passed") < 1 {
+		t.Errorf("Expected at least 1 file with correct license, got %d", processor.stats.Get("passed"))
 	}
-	if processor.stats["missing"] < 1 {
+	if processor.stats.Get("missing") < 1 {
 		t.Errorf(
 			"Expected at least 1 file with missing license, got %d",
-			processor.stats["missing"],
+			processor.stats.Get("missing"),
 		)
 	}
 }
@@ -526,8 +531,8 @@ func TestStatsTracking(t *testing.T) {
 		t.Fatalf("Add() failed: %v", err)
 	}
 
-	if processor.stats["added"] != 1 {
-		t.Errorf("Expected 1 file added, got %d", processor.stats["added"])
+	if processor.stats.Get("added") != 1 {
+		t.Errorf("Expected 1 file added, got %d", processor.stats.Get("added"))
 	}
 
 	// Now try to add to both files - one should be existing, one should be added
@@ -538,10 +543,347 @@ func TestStatsTracking(t *testing.T) {
 	}
 
 	// Verify stats - should have 1 added (another.go) and 1 existing (new.go)
-	if processor.stats["added"] != 1 {
-		t.Errorf("Expected 1 file added, got %d", processor.stats["added"])
+	if processor.stats.Get("added") != 1 {
+		t.Errorf("Expected 1 file added, got %d", processor.stats.Get("added"))
 	}
-	if processor.stats["existing"] != 1 {
-		t.Errorf("Expected 1 file with existing license, got %d", processor.stats["existing"])
+	if processor.stats.Get("existing") != 1 {
+		t.Errorf("Expected 1 file with existing license, got %d", processor.stats.Get("existing"))
+	}
+}
+
+// TestRulesSelectLicensePerPath tests that per-path rules pick the license and style per file
+func TestRulesSelectLicensePerPath(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	files := map[string]string{
+		"lib/lib.go":         "package lib\n",
+		"services/svc.go":    "package svc\n",
+		"examples/sample.go": "package main\n",
+		"third_party/foo.go": "package foo\n",
+		"third_party/bar.go": "package bar\n",
+	}
+	for path, content := range files {
+		fullPath := filepath.Join(tmpDir, path)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write file %s: %v", path, err)
+		}
+	}
+
+	cfg := &Config{
+		LicenseText:       "Licensed under the Apache License, Version 2.0",
+		Input:             filepath.Join(tmpDir, "**/*.go"),
+		PresetStyle:       "hash",
+		ForceCommentStyle: force.No,
+		LogLevel:          logger.ErrorLevel,
+		Rules: []Rule{
+			{
+				Patterns:    []string{filepath.Join(tmpDir, "services")},
+				LicenseText: "Proprietary and confidential",
+			},
+			{
+				Patterns:          []string{filepath.Join(tmpDir, "examples/**/*.go")},
+				LicenseText:       "MIT License",
+				PresetStyle:       "box",
+				ForceCommentStyle: force.Single,
+			},
+			{
+				Patterns:    []string{filepath.Join(tmpDir, "third_party/foo.go")}, // a single file
+				LicenseText: "BSD License",
+			},
+		},
+	}
+
+	if err := NewFileProcessor(cfg).Add(); err != nil {
+		t.Fatalf("Add() failed: %v", err)
+	}
+
+	expectations := map[string]string{
+		"lib/lib.go":         "Apache License",
+		"services/svc.go":    "Proprietary and confidential",
+		"examples/sample.go": "// MIT License",
+		"third_party/foo.go": "BSD License",
+		"third_party/bar.go": "Apache License",
+	}
+	for path, want := range expectations {
+		content, err := os.ReadFile(filepath.Join(tmpDir, path))
+		if err != nil {
+			t.Fatalf("Failed to read file %s: %v", path, err)
+		}
+		if !strings.Contains(string(content), want) {
+			t.Errorf("File %s should contain %q, got:\n%s", path, want, content)
+		}
+	}
+
+	// A single check run applies every rule
+	if err := NewFileProcessor(cfg).Check(); err != nil {
+		t.Errorf("Check() with rules failed: %v", err)
+	}
+}
+
+func TestRulesFromSubdirectory(t *testing.T) {
+	h := NewTestHelper(t, "Licensed under the Apache License, Version 2.0")
+	h.CreateFile("services/svc.go", "package svc\n")
+	h.CreateFile("lib/lib.go", "package lib\n")
+
+	// Rule patterns are relative to the config file, not the working directory
+	h.Chdir("services")
+	cfg := &Config{
+		LicenseText:       h.LicenseText(),
+		Input:             filepath.Join(h.TmpDir(), "**", "*.go"),
+		PresetStyle:       "hash",
+		ForceCommentStyle: force.Single,
+		LogLevel:          logger.ErrorLevel,
+		RuleDir:           h.TmpDir(),
+		Rules: []Rule{
+			{Patterns: []string{"services/**"}, LicenseText: "Proprietary and confidential"},
+			{Patterns: []string{"lib"}, ForceCommentStyle: force.No},
+		},
+	}
+	if err := NewFileProcessor(cfg).Add(); err != nil {
+		t.Fatalf("Add() failed: %v", err)
+	}
+
+	if svc := h.ReadFile(filepath.Join(h.TmpDir(), "services", "svc.go")); !strings.Contains(svc, "// Proprietary") {
+		t.Errorf("services/svc.go did not get the rule license as single-line comments:\n%s", svc)
+	}
+	// An explicit "no" turns the global --comments single off
+	if lib := h.ReadFile(filepath.Join(h.TmpDir(), "lib", "lib.go")); !strings.HasPrefix(lib, "/*") {
+		t.Errorf("lib/lib.go should use the default block comment:\n%s", lib)
+	}
+}
+
+func TestCheckRecords(t *testing.T) {
+	h := NewTestHelper(t, "Copyright (c) 2025 Test Corp")
+	good := h.CreateFile("good.go", "package main\n")
+	h.CreateFile("missing.py", "print('hi')\n")
+	h.AddLicenseToFile(good)
+
+	processor := h.CreateProcessor(filepath.Join(h.TmpDir(), "*.*"), force.No)
+	if err := processor.Check(); err == nil {
+		t.Fatal("Check() should fail for a file without license")
+	}
+
+	records := processor.Records()
+	if len(records) != 2 {
+		t.Fatalf("Expected 2 records, got %d", len(records))
+	}
+
+	byName := map[string]report.Record{}
+	for _, rec := range records {
+		byName[filepath.Base(rec.Path)] = rec
+	}
+
+	want := map[string]report.Record{
+		"good.go": {
+			Language: "go", Handler: "GoHandler", Style: "Hash", Status: "FullMatch",
+			Action: report.ActionChecked, StartLine: 2, EndLine: 4,
+		},
+		"missing.py": {
+			Language: "python", Handler: "PythonHandler", Status: "NoLicense",
+			Action: report.ActionChecked, Reason: "Missing license", StartLine: 1, EndLine: 1,
+		},
+	}
+	for name, w := range want {
+		got, ok := byName[name]
+		if !ok {
+			t.Errorf("No record for %s", name)
+			continue
+		}
+		w.Path = got.Path
+		if got != w {
+			t.Errorf("Record for %s = %+v, want %+v", name, got, w)
+		}
+	}
+
+	totals := processor.Report("check").Totals
+	if totals["files"] != 2 || totals["passed"] != 1 || totals["missing"] != 1 {
+		t.Errorf("Unexpected totals: %v", totals)
+	}
+}
+
+// TestParallelMatchesSerial verifies that a worker pool produces the same output,
+// records and stats as a serial run
+func TestParallelMatchesSerial(t *testing.T) {
+	h := NewTestHelper(t, "Copyright (c) 2025 Test Corp\nAll rights reserved.")
+	for i := 0; i < 40; i++ {
+		file := h.CreateFile(fmt.Sprintf("pkg%d/file%02d.go", i%4, i), "package main\n\nfunc main() {}\n")
+		if i%3 == 0 {
+			h.AddLicenseToFile(file)
+		}
+	}
+	h.CreateFile("script.py", "print('hello')\n")
+
+	run := func(jobs int) (string, []report.Record, map[string]int) {
+		var out bytes.Buffer
+		p := NewFileProcessor(&Config{
+			LicenseText:       h.LicenseText(),
+			Input:             filepath.Join(h.TmpDir(), "**", "*.*"),
+			PresetStyle:       "hash",
+			ForceCommentStyle: force.No,
+			LogLevel:          logger.InfoLevel,
+			LogOutput:         &out,
+			Jobs:              jobs,
+		})
+		if err := p.Check(); err == nil {
+			t.Fatalf("Check() with %d jobs should fail for missing licenses", jobs)
+		}
+		return out.String(), p.Records(), p.stats.Snapshot()
+	}
+
+	serialOut, serialRecords, serialStats := run(1)
+	for _, jobs := range []int{4, 0} {
+		out, records, stats := run(jobs)
+		if out != serialOut {
+			t.Errorf("Output with %d jobs differs from serial output:\n%s\nwant\n%s", jobs, out, serialOut)
+		}
+		if !reflect.DeepEqual(records, serialRecords) {
+			t.Errorf("Records with %d jobs differ from serial records", jobs)
+		}
+		if !reflect.DeepEqual(stats, serialStats) {
+			t.Errorf("Stats with %d jobs = %v, want %v", jobs, stats, serialStats)
+		}
+	}
+	if serialStats["missing"] != 27 {
+		t.Errorf("Expected 27 files with missing license, got %d", serialStats["missing"])
+	}
+}
+
+// TestIgnoreFiles verifies that discovery honors .gitignore and .licenseignore files
+func TestIgnoreFiles(t *testing.T) {
+	h := NewTestHelper(t, "Copyright (c) 2025 Test Corp")
+	h.CreateFile(".gitignore", "node_modules/\n*.gen.go\n!keep.gen.go\n")
+	h.CreateFile(".licenseignore", "vendor/\n")
+	h.CreateFile("main.go", "package main\n")
+	h.CreateFile("types.gen.go", "package main\n")
+	h.CreateFile("keep.gen.go", "package main\n")
+	h.CreateFile("node_modules/lib/index.js", "module.exports = {}\n")
+	h.CreateFile("vendor/dep/dep.go", "package dep\n")
+	h.CreateFile("web/.gitignore", "!*.gen.go\n")
+	h.CreateFile("web/app.gen.go", "package web\n")
+
+	tests := []struct {
+		name        string
+		input       string
+		noGitignore bool
+		want        []string
+	}{
+		{"glob", "**/*.*", false, []string{"keep.gen.go", "main.go", "web/app.gen.go"}},
+		{"directory glob", "*", false, []string{"keep.gen.go", "main.go", "web/app.gen.go"}},
+		{"literal path", "types.gen.go,main.go", false, []string{"main.go"}},
+		{"without gitignore", "**/*.*", true, []string{
+			"keep.gen.go", "main.go", "node_modules/lib/index.js", "types.gen.go", "web/app.gen.go",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var inputs []string
+			for _, input := range strings.Split(tt.input, ",") {
+				inputs = append(inputs, filepath.Join(h.TmpDir(), input))
+			}
+			p := NewFileProcessor(&Config{
+				Input:       strings.Join(inputs, ","),
+				NoGitignore: tt.noGitignore,
+				LogLevel:    logger.ErrorLevel,
+			})
+			files, err := p.PrepareOperation()
+			if err != nil {
+				t.Fatalf("PrepareOperation() failed: %v", err)
+			}
+			var got []string
+			for _, file := range files {
+				rel, _ := filepath.Rel(h.TmpDir(), file)
+				got = append(got, filepath.ToSlash(rel))
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Found %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestGitattributesExclusion verifies that generated and vendored files are reported
+// separately instead of failing the check
+func TestGitattributesExclusion(t *testing.T) {
+	h := NewTestHelper(t, "Copyright (c) 2025 Test Corp")
+	h.CreateFile(".gitattributes", "*.pb.go linguist-generated\nvendor/** linguist-vendored\n")
+	h.AddLicenseToFile(h.CreateFile("main.go", "package main\n"))
+	h.CreateFile("api.pb.go", "package main\n")
+	h.CreateFile("vendor/dep/dep.go", "package dep\n")
+
+	cfg := &Config{
+		LicenseText: h.LicenseText(),
+		Input:       filepath.Join(h.TmpDir(), "**", "*.go"),
+		PresetStyle: "hash",
+		LogLevel:    logger.ErrorLevel,
+	}
+	p := NewFileProcessor(cfg)
+	if err := p.Check(); err != nil {
+		t.Fatalf("Check() should pass when only excluded files lack a license: %v", err)
+	}
+	if p.stats.Get("generated") != 1 || p.stats.Get("vendored") != 1 || p.stats.Get("passed") != 1 {
+		t.Errorf("Unexpected stats: %v", p.stats.Snapshot())
+	}
+	for _, rec := range p.Records() {
+		if strings.HasSuffix(rec.Path, "dep.go") && (rec.Action != report.ActionSkipped || rec.SkipReason != "vendored") {
+			t.Errorf("Unexpected record for vendored file: %+v", rec)
+		}
+	}
+
+	cfg.NoGitattributes = true
+	if err := NewFileProcessor(cfg).Check(); err == nil {
+		t.Error("Check() without .gitattributes should fail for missing licenses")
+	}
+}
+
+func TestBumpYear(t *testing.T) {
+	h := NewTestHelper(t, "Copyright (c) 2024 Acme Corp")
+	licensed := h.CreateFile("licensed.go", "package main\n\n// Since 2020\n")
+	h.AddLicenseToFile(licensed)
+	plain := h.CreateFile("plain.go", "package main\n")
+	before := h.ReadFile(licensed)
+
+	cfg := &Config{
+		Input:    filepath.Join(h.TmpDir(), "*.go"),
+		Year:     "2026",
+		DryRun:   true,
+		ShowDiff: true,
+		LogLevel: logger.ErrorLevel,
+	}
+	var out bytes.Buffer
+	cfg.LogOutput = &out
+
+	processor := NewFileProcessor(cfg)
+	if err := processor.BumpYear(); err != nil {
+		t.Fatalf("BumpYear() failed: %v", err)
+	}
+	if h.ReadFile(licensed) != before {
+		t.Error("BumpYear() should not write files in dry-run mode")
+	}
+	if !strings.Contains(out.String(), "+ * Copyright (c) 2024-2026 Acme Corp") {
+		t.Errorf("BumpYear() should print the diff, got:\n%s", out.String())
+	}
+
+	actions := map[string]string{}
+	for _, rec := range processor.Records() {
+		actions[filepath.Base(rec.Path)] = rec.Action
+	}
+	if actions["licensed.go"] != report.ActionDryRun || actions["plain.go"] != report.ActionSkipped {
+		t.Errorf("BumpYear() actions = %v", actions)
+	}
+
+	cfg.DryRun = false
+	if err := NewFileProcessor(cfg).BumpYear(); err != nil {
+		t.Fatalf("BumpYear() failed: %v", err)
+	}
+	want := strings.Replace(before, "(c) 2024 ", "(c) 2024-2026 ", 1)
+	if got := h.ReadFile(licensed); got != want {
+		t.Errorf("BumpYear() wrote:\n%s\nwant:\n%s", got, want)
+	}
+	if got := h.ReadFile(plain); got != "package main\n" {
+		t.Errorf("BumpYear() changed a file without license: %q", got)
 	}
 }
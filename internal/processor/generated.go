@@ -0,0 +1,90 @@
+package processor
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/jeeftor/license-manager/internal/errors"
+	"github.com/jeeftor/license-manager/internal/ignore"
+	"github.com/jeeftor/license-manager/internal/language"
+	"github.com/jeeftor/license-manager/internal/license"
+	"github.com/jeeftor/license-manager/internal/report"
+	"github.com/jeeftor/license-manager/internal/styles"
+)
+
+// Generated code policies
+const (
+	GeneratedSkip    = "skip"    // Skip generated files in every command
+	GeneratedCheck   = "check"   // Check generated files, but never change them
+	GeneratedProcess = "process" // Treat generated files like any other file
+)
+
+// skipGenerated is the skip reason of generated files, the same as for files
+// marked linguist-generated in .gitattributes
+const skipGenerated = ignore.Generated
+
+// GeneratedPattern is a generated-code marker for one language
+type GeneratedPattern struct {
+	Language string         // Language name (go, python, ...) or file extension, "" for all
+	Pattern  *regexp.Regexp // Matched against comment text, without comment syntax
+}
+
+// ValidateGeneratedPolicy returns an error for unknown generated code policies
+func ValidateGeneratedPolicy(policy string) error {
+	switch policy {
+	case "", GeneratedSkip, GeneratedCheck, GeneratedProcess:
+		return nil
+	}
+	return errors.NewValidationError(
+		fmt.Sprintf("unknown generated code policy %q (supported: %s, %s, %s)",
+			policy, GeneratedSkip, GeneratedCheck, GeneratedProcess),
+		"GeneratedPolicy")
+}
+
+// generatedPatterns returns the markers of generated code in a file
+func (fp *FileProcessor) generatedPatterns(file string, commentStyle styles.CommentLanguage) []*regexp.Regexp {
+	ext := strings.TrimPrefix(strings.ToLower(filepath.Ext(file)), ".")
+	patterns := slices.Clone(language.DefaultGeneratedPatterns)
+	for _, p := range fp.config.GeneratedPatterns {
+		if p.Language == "" || p.Language == "*" || p.Language == commentStyle.Language || p.Language == ext {
+			patterns = append(patterns, p.Pattern)
+		}
+	}
+	return patterns
+}
+
+// skipGeneratedFile records a generated file as skipped when the policy leaves
+// it alone: always with GeneratedSkip, and in commands that change files with
+// GeneratedCheck. It reports whether the file was skipped.
+func (fp *FileProcessor) skipGeneratedFile(
+	rec *report.Record,
+	file string,
+	manager *license.LicenseManager,
+	commentStyle styles.CommentLanguage,
+	changes bool,
+) bool {
+	switch fp.config.GeneratedPolicy {
+	case GeneratedProcess:
+		return false
+	case GeneratedCheck:
+		if !changes {
+			return false
+		}
+	}
+
+	marker := language.MatchComment(
+		manager.CommentLines(language.GeneratedLines),
+		fp.generatedPatterns(file, commentStyle),
+	)
+	if marker == "" {
+		return false
+	}
+	fp.stats.Inc(skipGenerated)
+	fp.logger.LogInfo("Skipping generated file %s (%s)", relativePath(file), marker)
+	rec.Action = report.ActionSkipped
+	rec.SkipReason = skipGenerated
+	return true
+}
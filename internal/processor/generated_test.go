@@ -0,0 +1,79 @@
+package processor
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/jeeftor/license-manager/internal/logger"
+)
+
+func TestGeneratedCode(t *testing.T) {
+	h := NewTestHelper(t, "Copyright (c) 2025 Acme Corp")
+	files := map[string]string{
+		"api.pb.go": "// Code generated by protoc-gen-go. DO NOT EDIT.\n\npackage api\n",
+		"schema.py": "# AUTOGEN from schema.json\nFIELDS = []\n",
+		"main.go":   "package main\n",
+	}
+	generated := []string{"api.pb.go", "schema.py"}
+	reset := func() {
+		for name, content := range files {
+			h.CreateFile(name, content)
+		}
+	}
+	newProcessor := func(policy string) *FileProcessor {
+		return NewFileProcessor(&Config{
+			LicenseText:       h.LicenseText(),
+			Input:             filepath.Join(h.TmpDir(), "*.*"),
+			Skip:              "LICENSE",
+			PresetStyle:       "hash",
+			LogLevel:          logger.ErrorLevel,
+			GeneratedPolicy:   policy,
+			GeneratedPatterns: []GeneratedPattern{{Language: "python", Pattern: regexp.MustCompile(`^AUTOGEN\b`)}},
+		})
+	}
+
+	// Skipped by default, check passes without their license
+	reset()
+	p := newProcessor("")
+	if err := p.Add(); err != nil {
+		t.Fatalf("Add() failed: %v", err)
+	}
+	for _, name := range generated {
+		if h.ReadFile(filepath.Join(h.TmpDir(), name)) != files[name] {
+			t.Errorf("generated file %s was modified", name)
+		}
+	}
+	if p.stats.Get(skipGenerated) != 2 || p.stats.Get("added") != 1 {
+		t.Errorf("Unexpected stats: %v", p.stats.Snapshot())
+	}
+	if err := newProcessor(GeneratedSkip).Check(); err != nil {
+		t.Errorf("Check() should pass when only generated files lack a license: %v", err)
+	}
+
+	// Checked, but never changed
+	p = newProcessor(GeneratedCheck)
+	if err := p.Check(); err == nil {
+		t.Error("Check() with the check policy should fail for generated files without a license")
+	}
+	if err := p.Add(); err != nil {
+		t.Fatalf("Add() failed: %v", err)
+	}
+	for _, name := range generated {
+		if h.ReadFile(filepath.Join(h.TmpDir(), name)) != files[name] {
+			t.Errorf("generated file %s was modified with the check policy", name)
+		}
+	}
+
+	// Processed like any other file
+	reset()
+	if err := newProcessor(GeneratedProcess).Add(); err != nil {
+		t.Fatalf("Add() failed: %v", err)
+	}
+	for _, name := range generated {
+		if !strings.Contains(h.ReadFile(filepath.Join(h.TmpDir(), name)), "Acme Corp") {
+			t.Errorf("generated file %s has no license with the process policy", name)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package processor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jeeftor/license-manager/internal/git"
+)
+
+// usesGit reports whether files are selected from git
+func (c *Config) usesGit() bool {
+	return c.GitSince != "" || c.GitStaged || c.GitTrackedOnly
+}
+
+// openRepo opens the git repository of the working directory once
+func (fp *FileProcessor) openRepo() error {
+	if fp.repo != nil {
+		return nil
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	fp.repo, err = git.Open(cwd)
+	return err
+}
+
+// selectGitFiles keeps the files that every configured git selection lists
+func (fp *FileProcessor) selectGitFiles(files []string) ([]string, error) {
+	if err := fp.openRepo(); err != nil {
+		return nil, err
+	}
+	repo := fp.repo
+
+	var selections []map[string]bool
+	var modes []string
+	add := func(mode string, list func() ([]string, error)) error {
+		listed, err := list()
+		if err != nil {
+			return err
+		}
+		set := make(map[string]bool, len(listed))
+		for _, file := range listed {
+			set[canonicalPath(file)] = true
+		}
+		selections = append(selections, set)
+		modes = append(modes, mode)
+		return nil
+	}
+
+	if fp.config.GitSince != "" {
+		if err := add("changed since "+fp.config.GitSince, func() ([]string, error) {
+			return repo.ChangedSince(fp.config.GitSince)
+		}); err != nil {
+			return nil, err
+		}
+	}
+	if fp.config.GitStaged {
+		if err := add("staged", repo.Staged); err != nil {
+			return nil, err
+		}
+	}
+	if fp.config.GitTrackedOnly {
+		if err := add("tracked", repo.Tracked); err != nil {
+			return nil, err
+		}
+	}
+
+	var selected []string
+	for _, file := range files {
+		path := canonicalPath(file)
+		keep := true
+		for _, set := range selections {
+			keep = keep && set[path]
+		}
+		if keep {
+			selected = append(selected, file)
+		} else {
+			fp.logger.LogDebug("Not selected by git: %s", relativePath(file))
+		}
+	}
+
+	fp.logger.LogInfo("Selected %d of %d files (%s)", len(selected), len(files), strings.Join(modes, ", "))
+	if len(selected) == 0 {
+		fp.logger.LogNotice("No %s files match the input patterns", strings.Join(modes, ", "))
+	}
+	return selected, nil
+}
+
+// canonicalPath resolves symlinks so paths from git and the filesystem compare equal
+func canonicalPath(path string) string {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		return resolved
+	}
+	return filepath.Clean(path)
+}
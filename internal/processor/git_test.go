@@ -0,0 +1,136 @@
+package processor
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jeeftor/license-manager/internal/license"
+	"github.com/jeeftor/license-manager/internal/logger"
+)
+
+// TestCommitYearPolicy verifies that check and update take the years from the
+// history of a real repository
+func TestCommitYearPolicy(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	h := NewTestHelper(t, "Copyright (c) {{.Year}} Test Corp")
+	git := func(date string, args ...string) {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=Test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Dir = h.TmpDir()
+		if date != "" {
+			cmd.Env = append(os.Environ(), "GIT_AUTHOR_DATE="+date, "GIT_COMMITTER_DATE="+date)
+		}
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+	}
+	git("", "init", "-q")
+	old := h.CreateFile("old.go", "package main\n")
+	git("", "add", "old.go")
+	git("2019-03-01T12:00:00", "commit", "-q", "-m", "old")
+	h.CreateFile("old.go", "package main\n\nfunc main() {}\n")
+	git("2021-03-01T12:00:00", "commit", "-q", "-a", "-m", "edit")
+	recent := h.CreateFile("recent.go", "package main\n")
+	git("", "add", "recent.go")
+	git("2023-03-01T12:00:00", "commit", "-q", "-m", "recent")
+	h.Chdir("")
+
+	newConfig := func() *Config {
+		return &Config{
+			LicenseText: h.LicenseText(),
+			Input:       "*.go",
+			PresetStyle: "hash",
+			YearPolicy:  license.YearCommitRange,
+			LogLevel:    logger.ErrorLevel,
+		}
+	}
+	if err := NewFileProcessor(newConfig()).Add(); err != nil {
+		t.Fatalf("Add() failed: %v", err)
+	}
+	if got := h.ReadFile(old); !strings.Contains(got, "Copyright (c) 2019-2021 Test Corp") {
+		t.Errorf("Add() should use the commit range, got:\n%s", got)
+	}
+	if got := h.ReadFile(recent); !strings.Contains(got, "Copyright (c) 2023 Test Corp") {
+		t.Errorf("Add() should use the single commit year, got:\n%s", got)
+	}
+	if err := NewFileProcessor(newConfig()).Check(); err != nil {
+		t.Errorf("Check() after Add() failed: %v", err)
+	}
+
+	git("2024-03-01T12:00:00", "commit", "-q", "-a", "-m", "license")
+	if err := NewFileProcessor(newConfig()).Check(); err == nil {
+		t.Error("Check() should fail once the history moves past the header")
+	}
+	if err := NewFileProcessor(newConfig()).Update(); err != nil {
+		t.Fatalf("Update() failed: %v", err)
+	}
+	if got := h.ReadFile(old); !strings.Contains(got, "Copyright (c) 2019-2024 Test Corp") {
+		t.Errorf("Update() should extend the range, got:\n%s", got)
+	}
+	if err := NewFileProcessor(newConfig()).Check(); err != nil {
+		t.Errorf("Check() after Update() failed: %v", err)
+	}
+}
+
+// TestGitSelection verifies that git selections narrow down the input patterns
+func TestGitSelection(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	h := NewTestHelper(t, "Copyright (c) 2025 Test Corp")
+	h.CreateFile("tracked.go", "package main\n")
+	h.CreateFile("staged.go", "package main\n")
+	h.CreateFile("untracked.go", "package main\n")
+
+	git := func(args ...string) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = h.TmpDir()
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+	}
+	git("init", "-q")
+	git("add", "tracked.go")
+	git("-c", "user.name=Test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "initial")
+	git("add", "staged.go")
+
+	h.Chdir("")
+
+	tests := []struct {
+		name   string
+		config Config
+		want   []string
+	}{
+		{"all", Config{}, []string{"staged.go", "tracked.go", "untracked.go"}},
+		{"tracked only", Config{GitTrackedOnly: true}, []string{"staged.go", "tracked.go"}},
+		{"staged", Config{GitStaged: true}, []string{"staged.go"}},
+		{"since HEAD", Config{GitSince: "HEAD"}, []string{"staged.go", "untracked.go"}},
+		{"since HEAD and tracked", Config{GitSince: "HEAD", GitTrackedOnly: true}, []string{"staged.go"}},
+		{"skip", Config{GitSince: "HEAD", Skip: "staged*"}, []string{"untracked.go"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := tt.config
+			cfg.Input = "*.go"
+			cfg.LogLevel = logger.ErrorLevel
+			files, err := NewFileProcessor(&cfg).PrepareOperation()
+			if err != nil {
+				t.Fatalf("PrepareOperation() failed: %v", err)
+			}
+			var got []string
+			for _, file := range files {
+				got = append(got, filepath.Base(file))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Selected %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package processor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jeeftor/license-manager/internal/errors"
+	"github.com/jeeftor/license-manager/internal/report"
+)
+
+// outputPath returns the mirror of a file under the output directory. Files
+// outside the working directory have no mirror.
+func (fp *FileProcessor) outputPath(file string) (string, error) {
+	rel := relativePath(file)
+	if filepath.IsAbs(rel) || rel == ".." || strings.HasPrefix(rel, "../") {
+		return "", errors.NewFileError("file is outside the working directory and has no place in the output directory", file, "write")
+	}
+	return filepath.Join(fp.config.OutputDir, filepath.FromSlash(rel)), nil
+}
+
+// writeFile writes the new content of a file in place, recording it in the undo
+// journal, or to its mirror under the output directory, in the encoding the file
+// was read with. Nothing is written when changes go to a patch file.
+func (fp *FileProcessor) writeFile(file, content string) error {
+	content, err := fp.fileHandler.Encode(file, content)
+	if err != nil {
+		return err
+	}
+	if fp.config.PatchFile != "" {
+		return nil
+	}
+	if fp.config.OutputDir == "" {
+		write := func() error { return fp.fileHandler.WriteFile(file, content) }
+		if fp.journal != nil {
+			return fp.journal.Record(file, content, write)
+		}
+		return write()
+	}
+
+	target, err := fp.outputPath(file)
+	if err != nil {
+		return err
+	}
+	fp.logger.LogInfo("  Writing %s", target)
+	return fp.fileHandler.WriteFileTo(file, target, content)
+}
+
+// logWritten counts and reports a change written by writeFile. Changes that went
+// to the patch file are counted apart, as the file itself was not changed.
+func (fp *FileProcessor) logWritten(file, stat, format string, args ...interface{}) {
+	if fp.config.PatchFile != "" {
+		fp.stats.Inc("patched")
+		fp.logger.LogSuccess("Wrote change for %s to %s", file, fp.config.PatchFile)
+		return
+	}
+	fp.stats.Inc(stat)
+	fp.logger.LogSuccess(format, args...)
+}
+
+// written reports whether an action wrote the file
+func written(action string) bool {
+	return action == report.ActionAdded || action == report.ActionUpdated || action == report.ActionRemoved
+}
+
+// copyUnchanged wraps process to copy files that it did not write to the output
+// directory, when configured, so the output tree holds every processed file
+func copyUnchanged(
+	process func(fp *FileProcessor, file string) fileResult,
+) func(fp *FileProcessor, file string) fileResult {
+	return func(fp *FileProcessor, file string) fileResult {
+		res := process(fp, file)
+		action := res.record.Action
+		if fp.config.OutputDir == "" || !fp.config.CopyUnchanged || fp.config.DryRun ||
+			written(action) || action == report.ActionFailed || res.err != nil {
+			return res
+		}
+
+		err := fp.copyToOutput(file)
+		if err != nil {
+			res.record = fp.failRecord(res.record, file, "copy", err)
+		}
+		return res
+	}
+}
+
+// copyToOutput copies a file unchanged to its mirror under the output directory
+func (fp *FileProcessor) copyToOutput(file string) error {
+	target, err := fp.outputPath(file)
+	if err != nil {
+		return err
+	}
+	content, err := os.ReadFile(file) // as is, whatever its encoding
+	if err != nil {
+		return errors.NewFileError("failed to read file", file, "read")
+	}
+	if err := fp.fileHandler.WriteFileTo(file, target, string(content)); err != nil {
+		return err
+	}
+
+	fp.stats.Inc("copied")
+	fp.logger.LogInfo("Copied unchanged %s", relativePath(file))
+	return nil
+}
+
+// dirSkip returns the skip pattern for a directory the processor writes to, such
+// as the output directory, so that its files are not processed, or "" when it
+// is outside the working directory
+func dirSkip(dir string) string {
+	if dir == "" {
+		return ""
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+	rel := relativePath(abs)
+	if filepath.IsAbs(rel) || rel == "." || rel == ".." || strings.HasPrefix(rel, "../") {
+		return ""
+	}
+	return rel
+}
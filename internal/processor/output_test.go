@@ -0,0 +1,62 @@
+package processor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jeeftor/license-manager/internal/logger"
+)
+
+func TestOutputDir(t *testing.T) {
+	h := NewTestHelper(t, "Copyright (c) 2025 Acme Corp")
+	licensed := h.CreateFile("src/licensed.go", "package main\n")
+	h.AddLicenseToFile(licensed)
+	licensedContent := h.ReadFile(licensed)
+	script := h.CreateFile("src/tools/run.sh", "#!/bin/sh\necho hi\n")
+	if err := os.Chmod(script, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	h.Chdir("")
+
+	cfg := &Config{
+		LicenseText:   h.LicenseText(),
+		Input:         "**/*.go,**/*.sh",
+		PresetStyle:   "hash",
+		OutputDir:     "dist",
+		CopyUnchanged: true,
+		LogLevel:      logger.ErrorLevel,
+	}
+	processor := NewFileProcessor(cfg)
+	if err := processor.Add(); err != nil {
+		t.Fatalf("Add() failed: %v", err)
+	}
+
+	if got := h.ReadFile(script); got != "#!/bin/sh\necho hi\n" {
+		t.Errorf("Add() modified the source file: %q", got)
+	}
+	out := filepath.Join(h.TmpDir(), "dist", "src", "tools", "run.sh")
+	if got := h.ReadFile(out); !strings.Contains(got, "Copyright (c) 2025 Acme Corp") {
+		t.Errorf("output file has no license:\n%s", got)
+	}
+	if info, err := os.Stat(out); err != nil || info.Mode().Perm() != 0755 {
+		t.Errorf("output file mode = %v, %v, want 0755", info.Mode(), err)
+	}
+	if got := h.ReadFile(filepath.Join(h.TmpDir(), "dist", "src", "licensed.go")); got != licensedContent {
+		t.Errorf("unchanged file was not copied as is:\n%s", got)
+	}
+	if got := processor.stats.Get("copied"); got != 1 {
+		t.Errorf("copied = %d, want 1", got)
+	}
+
+	// The output directory is not processed again
+	processor = NewFileProcessor(cfg)
+	if err := processor.Add(); err != nil {
+		t.Fatalf("second Add() failed: %v", err)
+	}
+	if got := len(processor.Records()); got != 2 {
+		t.Errorf("second Add() processed %d files, want 2", got)
+	}
+}
@@ -0,0 +1,10 @@
+//go:build !unix
+
+package processor
+
+import "os"
+
+// chownLike does nothing on platforms without unix file ownership
+func chownLike(f *os.File, like os.FileInfo) error {
+	return nil
+}
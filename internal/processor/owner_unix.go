@@ -0,0 +1,25 @@
+//go:build unix
+
+package processor
+
+import (
+	"os"
+	"syscall"
+)
+
+// chownLike gives f the owner and group of like. Nothing is changed when they
+// already match, so unprivileged users can still write their own files.
+func chownLike(f *os.File, like os.FileInfo) error {
+	want, ok := like.Sys().(*syscall.Stat_t)
+	if !ok {
+		return nil
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if have, ok := info.Sys().(*syscall.Stat_t); ok && have.Uid == want.Uid && have.Gid == want.Gid {
+		return nil
+	}
+	return f.Chown(int(want.Uid), int(want.Gid))
+}
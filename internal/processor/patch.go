@@ -0,0 +1,176 @@
+package processor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/jeeftor/license-manager/internal/charset"
+	"github.com/jeeftor/license-manager/internal/diff"
+	"github.com/jeeftor/license-manager/internal/errors"
+	"github.com/jeeftor/license-manager/internal/git"
+	"github.com/jeeftor/license-manager/internal/journal"
+	"github.com/jeeftor/license-manager/internal/language"
+	"github.com/jeeftor/license-manager/internal/report"
+)
+
+// Patch file formats
+const (
+	PatchFormatPatch = "patch" // git diff output, applied with git apply
+	PatchFormatMbox  = "mbox"  // git format-patch output, applied with git am
+)
+
+// defaultIdent is the mbox author when git has no identity configured
+const defaultIdent = "license-manager <license-manager@localhost>"
+
+// ValidatePatchFormat returns an error for unknown patch formats
+func ValidatePatchFormat(format string) error {
+	switch format {
+	case "", PatchFormatPatch, PatchFormatMbox:
+		return nil
+	}
+	return errors.NewValidationError(
+		fmt.Sprintf("unknown patch format %q (supported: %s, %s)", format, PatchFormatPatch, PatchFormatMbox),
+		"PatchFormat")
+}
+
+// recordsDiffs reports whether changes are rendered as diffs
+func (c *Config) recordsDiffs() bool {
+	return c.ShowDiff || c.PatchFile != ""
+}
+
+// diffPath returns the path of a file in diffs, relative to the repository root
+// inside a git repository and to the working directory otherwise
+func (fp *FileProcessor) diffPath(file string) string {
+	if fp.repo != nil {
+		abs, err := filepath.Abs(file)
+		if err == nil {
+			rel, err := filepath.Rel(canonicalPath(fp.repo.Root), canonicalPath(abs))
+			if err == nil && !strings.HasPrefix(rel, "..") {
+				return filepath.ToSlash(rel)
+			}
+		}
+	}
+	return relativePath(file)
+}
+
+// showDiff records the change to a file as a git-style diff and prints it when
+// diffs were requested. On a terminal the zero-width markers are made visible,
+// otherwise the diff is printed as is so it can be applied with git apply. Files
+// in other encodings than UTF-8 are diffed as the bytes writeFile would write.
+func (fp *FileProcessor) showDiff(rec *report.Record, file, before, after string) error {
+	if !fp.config.recordsDiffs() {
+		return nil
+	}
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(file); err == nil {
+		mode = info.Mode()
+	}
+	path := fp.diffPath(file)
+	text := diff.GitFile(path, mode, before, after)
+	patch := text
+
+	if enc := fp.fileHandler.encoding(file); enc.Name != charset.UTF8 {
+		if !enc.LineBased() {
+			if fp.config.PatchFile != "" {
+				return errors.NewFileError("cannot write a patch for a file in "+enc.String()+", git treats it as binary", file, "diff")
+			}
+		} else {
+			encodedBefore, err := fp.fileHandler.Encode(file, before)
+			if err != nil {
+				return err
+			}
+			encodedAfter, err := fp.fileHandler.Encode(file, after)
+			if err != nil {
+				return err
+			}
+			patch = diff.GitFile(path, mode, encodedBefore, encodedAfter)
+		}
+	}
+	rec.Diff = patch
+
+	if !fp.config.ShowDiff {
+		return nil
+	}
+	if color.NoColor {
+		fmt.Fprint(fp.logger, rec.Diff)
+		return nil
+	}
+	fmt.Fprint(fp.logger, visibleMarkers(text))
+	return nil
+}
+
+// markerNames makes the zero-width license markers visible in terminal output
+var markerNames = strings.NewReplacer(
+	language.MarkerStart, color.New(color.FgYellow).Sprint("<ZWSP>"),
+	language.MarkerEnd, color.New(color.FgYellow).Sprint("<ZWNJ>"),
+)
+
+// visibleMarkers replaces the zero-width license markers with their names
+func visibleMarkers(text string) string {
+	return markerNames.Replace(text)
+}
+
+// finishChanges reports the undo journal of the last operation and writes its
+// changes to the patch file, if one is configured. subject is the commit
+// subject of the mbox format.
+func (fp *FileProcessor) finishChanges(subject string) error {
+	if fp.journal != nil && fp.journal.ID != "" {
+		fp.logger.LogNotice("Recorded run %s, revert it with: license-manager undo", fp.journal.ID)
+		if err := journal.Prune(fp.config.StateDir, journal.KeepRuns); err != nil {
+			fp.logger.LogWarning("Failed to remove old runs: %v", err)
+		}
+	}
+	if fp.config.PatchFile == "" {
+		return nil
+	}
+
+	var patch strings.Builder
+	changes := 0
+	for _, rec := range fp.records {
+		if rec.Diff == "" || rec.Action == report.ActionSkipped {
+			continue
+		}
+		patch.WriteString(rec.Diff)
+		changes++
+	}
+
+	content := patch.String()
+	if fp.config.PatchFormat == PatchFormatMbox && changes > 0 {
+		content = mbox(fp.patchIdent(), subject, time.Now(), content)
+	}
+	if err := os.WriteFile(fp.config.PatchFile, []byte(content), 0644); err != nil {
+		return errors.NewFileError("failed to write patch file", fp.config.PatchFile, "write")
+	}
+
+	fp.logger.LogNotice("Wrote %d changes to %s, no files were modified", changes, fp.config.PatchFile)
+	return nil
+}
+
+// patchIdent returns the author of mbox patches
+func (fp *FileProcessor) patchIdent() string {
+	dir := "."
+	if fp.repo != nil {
+		dir = fp.repo.Root
+	}
+	if ident := git.Ident(dir); ident != "" {
+		return ident
+	}
+	return defaultIdent
+}
+
+// mbox wraps a patch in a single git format-patch message
+func mbox(from, subject string, date time.Time, patch string) string {
+	var sb strings.Builder
+	sb.WriteString("From 0000000000000000000000000000000000000000 Mon Sep 17 00:00:00 2001\n")
+	fmt.Fprintf(&sb, "From: %s\n", from)
+	fmt.Fprintf(&sb, "Date: %s\n", date.Format(time.RFC1123Z))
+	fmt.Fprintf(&sb, "Subject: [PATCH] %s\n\n", subject)
+	sb.WriteString("---\n")
+	sb.WriteString(patch)
+	sb.WriteString("-- \nlicense-manager\n\n")
+	return sb.String()
+}
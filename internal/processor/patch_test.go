@@ -0,0 +1,173 @@
+package processor
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jeeftor/license-manager/internal/charset"
+	"github.com/jeeftor/license-manager/internal/language"
+	"github.com/jeeftor/license-manager/internal/logger"
+	"github.com/jeeftor/license-manager/internal/report"
+)
+
+func TestDiffAppliesWithGit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	h := NewTestHelper(t, "Copyright (c) 2025 Acme Corp")
+	file := h.CreateFile("main.go", "package main\n\nfunc main() {}")
+	before := h.ReadFile(file)
+
+	h.Chdir("")
+
+	var out bytes.Buffer
+	cfg := &Config{
+		LicenseText: h.LicenseText(),
+		Input:       "main.go",
+		PresetStyle: "hash",
+		DryRun:      true,
+		ShowDiff:    true,
+		LogLevel:    logger.ErrorLevel,
+		LogOutput:   &out,
+	}
+	processor := NewFileProcessor(cfg)
+	if err := processor.Add(); err != nil {
+		t.Fatalf("Add() failed: %v", err)
+	}
+	if h.ReadFile(file) != before {
+		t.Fatal("Add() should not write files in dry-run mode")
+	}
+	if rec := processor.Records()[0]; rec.Diff != out.String() {
+		t.Errorf("record diff and printed diff differ:\n%s\n%s", rec.Diff, out.String())
+	}
+	if !strings.Contains(out.String(), language.MarkerStart) {
+		t.Errorf("diff should keep the license markers:\n%s", out.String())
+	}
+
+	patch := filepath.Join(t.TempDir(), "license.patch")
+	if err := os.WriteFile(patch, out.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if msg, err := exec.Command("git", "apply", patch).CombinedOutput(); err != nil {
+		t.Fatalf("git apply failed: %v\n%s\n%s", err, msg, out.String())
+	}
+	applied := h.ReadFile(file)
+
+	// The patched file must match what add writes itself
+	if err := os.WriteFile(file, []byte(before), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg.DryRun, cfg.ShowDiff = false, false
+	if err := NewFileProcessor(cfg).Add(); err != nil {
+		t.Fatalf("Add() failed: %v", err)
+	}
+	if want := h.ReadFile(file); applied != want {
+		t.Errorf("git apply wrote:\n%s\nwant:\n%s", applied, want)
+	}
+}
+
+func TestPatchFile(t *testing.T) {
+	h := NewTestHelper(t, "Copyright (c) 2025 Acme Corp")
+	script := h.CreateFile("run.sh", "#!/bin/sh\necho hi")
+	if err := os.Chmod(script, 0755); err != nil {
+		t.Fatal(err)
+	}
+	source := h.CreateFile("main.go", "package main\n")
+
+	h.Chdir("")
+
+	for _, format := range []string{PatchFormatPatch, PatchFormatMbox} {
+		t.Run(format, func(t *testing.T) {
+			patch := filepath.Join(t.TempDir(), "license."+format)
+			cfg := &Config{
+				LicenseText: h.LicenseText(),
+				Input:       "*.sh,*.go",
+				PresetStyle: "hash",
+				PatchFile:   patch,
+				PatchFormat: format,
+				LogLevel:    logger.ErrorLevel,
+			}
+			processor := NewFileProcessor(cfg)
+			if err := processor.Add(); err != nil {
+				t.Fatalf("Add() failed: %v", err)
+			}
+			if patched, added := processor.stats.Get("patched"), processor.stats.Get("added"); patched != 2 || added != 0 {
+				t.Errorf("stats patched = %d, added = %d, want the files counted as patched", patched, added)
+			}
+
+			if got := h.ReadFile(script); got != "#!/bin/sh\necho hi" {
+				t.Errorf("Add() modified %s: %q", script, got)
+			}
+			if got := h.ReadFile(source); got != "package main\n" {
+				t.Errorf("Add() modified %s: %q", source, got)
+			}
+
+			content := h.ReadFile(patch)
+			for _, want := range []string{
+				"diff --git a/run.sh b/run.sh\nindex fbf5376..f0808a6 100755\n",
+				"diff --git a/main.go b/main.go\nindex 06ab7d0..7880f3f 100644\n",
+				" #!/bin/sh\n+#",
+				" echo hi\n\\ No newline at end of file\n",
+			} {
+				if !strings.Contains(content, want) {
+					t.Errorf("patch does not contain %q:\n%s", want, content)
+				}
+			}
+			isMbox := strings.Contains(content, "\nSubject: [PATCH] Add license headers\n")
+			if isMbox != (format == PatchFormatMbox) {
+				t.Errorf("%s patch has mbox headers = %v:\n%s", format, isMbox, content)
+			}
+		})
+	}
+}
+
+func TestEncodedPatch(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	h := NewTestHelper(t, "Copyright (c) 2025 Acme Corp")
+	original := "int caf\xe9 = 1;\n"
+	file := h.CreateFile("latin.c", original)
+	h.CreateFile("wide.c", "\xff\xfei\x00\n\x00")
+	h.Chdir("")
+
+	patch := filepath.Join(t.TempDir(), "license.patch")
+	cfg := &Config{
+		LicenseText: h.LicenseText(),
+		Input:       "*.c",
+		PresetStyle: "hash",
+		PatchFile:   patch,
+		Encodings:   []EncodingRule{{Pattern: "**/latin.c", Encoding: charset.Latin1}},
+		LogLevel:    logger.ErrorLevel,
+	}
+	processor := NewFileProcessor(cfg)
+	_ = processor.Add()
+	for _, rec := range processor.Records() {
+		if rec.Path == "wide.c" && (rec.Action != report.ActionFailed || rec.Diff != "") {
+			t.Errorf("wide.c recorded as %s with diff %q, want failed", rec.Action, rec.Diff)
+		}
+	}
+
+	if msg, err := exec.Command("git", "apply", patch).CombinedOutput(); err != nil {
+		t.Fatalf("git apply failed: %v\n%s", err, msg)
+	}
+	applied := h.ReadFile(file)
+
+	// The patched file must match what add writes itself
+	if err := os.WriteFile(file, []byte(original), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg.PatchFile, cfg.Input = "", "latin.c"
+	if err := NewFileProcessor(cfg).Add(); err != nil {
+		t.Fatalf("Add() failed: %v", err)
+	}
+	if want := h.ReadFile(file); applied != want {
+		t.Errorf("git apply wrote %q, want %q", applied, want)
+	}
+}
@@ -0,0 +1,29 @@
+package processor
+
+import (
+	"github.com/jeeftor/license-manager/internal/license"
+	"github.com/jeeftor/license-manager/internal/report"
+)
+
+// skipPragma is the skip reason of files excluded by a license-manager pragma
+const skipPragma = "pragma"
+
+// skipPragmaFile records a file excluded by a pragma comment as skipped. It
+// reports whether the file was skipped.
+func (fp *FileProcessor) skipPragmaFile(rec *report.Record, file string, manager *license.LicenseManager) bool {
+	pragma, applies := manager.Pragma()
+	if pragma == "" {
+		return false
+	}
+	if !applies {
+		fp.logger.LogWarning("Ignoring pragma license-manager: %s in %s, no comment block follows it",
+			pragma, relativePath(file))
+		return false
+	}
+
+	fp.stats.Inc(skipPragma)
+	fp.logger.LogInfo("Skipping %s (excluded by pragma license-manager: %s)", relativePath(file), pragma)
+	rec.Action = report.ActionSkipped
+	rec.SkipReason = skipPragma
+	return true
+}
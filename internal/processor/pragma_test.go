@@ -0,0 +1,48 @@
+package processor
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jeeftor/license-manager/internal/force"
+	"github.com/jeeftor/license-manager/internal/report"
+)
+
+func TestPragma(t *testing.T) {
+	h := NewTestHelper(t, "Copyright (c) 2025 Acme Corp")
+	files := map[string]string{
+		"fixture.go": "// license-manager: ignore\npackage fixtures\n",
+		"snippet.js": "// license-manager: ignore-next-block\n/*\n * Copyright Other Corp\n * SPDX-License-Identifier: Apache-2.0\n */\nvar a;\n",
+		"stale.js":   "// license-manager: ignore-next-block\nvar b;\n",
+	}
+	for name, content := range files {
+		h.CreateFile(name, content)
+	}
+
+	processor := h.CreateProcessor(filepath.Join(h.TmpDir(), "*.*"), force.No)
+	processor.config.Skip = "LICENSE"
+	if err := processor.Add(); err != nil {
+		t.Fatalf("Add() failed: %v", err)
+	}
+	for _, name := range []string{"fixture.go", "snippet.js"} {
+		if got := h.ReadFile(filepath.Join(h.TmpDir(), name)); got != files[name] {
+			t.Errorf("%s excluded by pragma was modified:\n%s", name, got)
+		}
+	}
+	if !strings.Contains(h.ReadFile(filepath.Join(h.TmpDir(), "stale.js")), "Acme Corp") {
+		t.Error("stale.js has no license, its pragma has no comment block to apply to")
+	}
+	if processor.stats.Get(skipPragma) != 2 {
+		t.Errorf("Unexpected stats: %v", processor.stats.Snapshot())
+	}
+
+	if err := processor.Check(); err != nil {
+		t.Fatalf("Check() should pass when only files excluded by pragma lack a license: %v", err)
+	}
+	for _, rec := range processor.Records() {
+		if filepath.Base(rec.Path) == "fixture.go" && (rec.Action != report.ActionSkipped || rec.SkipReason != skipPragma) {
+			t.Errorf("Unexpected record for file excluded by pragma: %+v", rec)
+		}
+	}
+}
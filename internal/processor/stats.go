@@ -0,0 +1,49 @@
+package processor
+
+import "sync"
+
+// Stats counts per-file outcomes of an operation. It is safe for concurrent use.
+type Stats struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+// NewStats creates an empty Stats
+func NewStats() *Stats {
+	return &Stats{counts: make(map[string]int)}
+}
+
+// Inc increments the counter for key
+func (s *Stats) Inc(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.counts[key]++
+}
+
+// Get returns the counter for key
+func (s *Stats) Get(key string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.counts[key]
+}
+
+// Reset sets the given counters to zero and drops all others
+func (s *Stats) Reset(keys ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.counts = make(map[string]int, len(keys))
+	for _, key := range keys {
+		s.counts[key] = 0
+	}
+}
+
+// Snapshot returns a copy of all counters
+func (s *Stats) Snapshot() map[string]int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	counts := make(map[string]int, len(s.counts))
+	for key, count := range s.counts {
+		counts[key] = count
+	}
+	return counts
+}
@@ -105,3 +105,21 @@ func (h *TestHelper) CreateProcessor(
 	}
 	return NewFileProcessor(cfg)
 }
+
+// Chdir changes the working directory to dir, relative to the temporary
+// directory, and restores it when the test ends
+func (h *TestHelper) Chdir(dir string) {
+	h.t.Helper()
+	wd, err := os.Getwd()
+	if err != nil {
+		h.t.Fatalf("Failed to get working directory: %v", err)
+	}
+	if err := os.Chdir(filepath.Join(h.tmpDir, dir)); err != nil {
+		h.t.Fatalf("Failed to change directory: %v", err)
+	}
+	h.t.Cleanup(func() {
+		if err := os.Chdir(wd); err != nil {
+			h.t.Errorf("Failed to restore working directory: %v", err)
+		}
+	})
+}
@@ -0,0 +1,96 @@
+package processor
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jeeftor/license-manager/internal/errors"
+	"github.com/jeeftor/license-manager/internal/journal"
+	"github.com/jeeftor/license-manager/internal/report"
+)
+
+// startJournal starts the undo journal of an operation that writes files in place
+func (fp *FileProcessor) startJournal(command string) {
+	fp.journal = nil
+	if fp.config.StateDir == "" || fp.config.DryRun || fp.config.PatchFile != "" || fp.config.OutputDir != "" {
+		return
+	}
+	fp.journal = journal.New(fp.config.StateDir, command)
+}
+
+// Runs returns the runs that can be undone, oldest first
+func (fp *FileProcessor) Runs() ([]*journal.Journal, error) {
+	if fp.config.StateDir == "" {
+		return nil, errors.NewValidationError("the undo journal is disabled", "StateDir")
+	}
+	return journal.List(fp.config.StateDir)
+}
+
+// Undo restores the files changed by a run, the last one when id is empty, and
+// removes the run. It refuses when files changed since the run, unless force is set.
+// In dry-run mode the files that would be restored are only reported.
+func (fp *FileProcessor) Undo(id string, force bool) error {
+	fp.resetStats()
+	fp.records = nil
+
+	if fp.config.PatchFile != "" || fp.config.OutputDir != "" {
+		return errors.NewValidationError("undo restores files in place and does not support --patch or --output-dir", "Undo")
+	}
+
+	runs, err := fp.Runs()
+	if err != nil {
+		return err
+	}
+	var run *journal.Journal
+	for _, r := range runs {
+		if id == "" || r.ID == id {
+			run = r
+		}
+	}
+	if run == nil {
+		if id == "" {
+			return errors.NewValidationError("no runs to undo in "+fp.config.StateDir, "StateDir")
+		}
+		return errors.NewValidationError("no run "+id+" in "+fp.config.StateDir, "StateDir")
+	}
+
+	if changed := run.Changed(); len(changed) > 0 && !force {
+		for i, path := range changed {
+			changed[i] = relativePath(path)
+		}
+		return errors.NewValidationError(
+			fmt.Sprintf("files changed since run %s, use --force to overwrite them: %s", run.ID, strings.Join(changed, ", ")),
+			"Undo")
+	}
+
+	fp.logger.LogNotice("Undoing run %s (%s, %d files)", run.ID, run.Command, len(run.Files))
+	for _, entry := range run.Files {
+		rec := report.Record{Path: relativePath(entry.Path)}
+		original, err := run.Original(entry)
+		if err == nil && fp.config.DryRun {
+			fp.logger.LogNotice("Would restore %s", rec.Path)
+			rec.Action = report.ActionDryRun
+			fp.records = append(fp.records, rec)
+			continue
+		}
+		if err == nil {
+			err = fp.fileHandler.WriteFile(entry.Path, original)
+		}
+		if err != nil {
+			fp.records = append(fp.records, fp.failRecord(rec, entry.Path, "restore", err))
+			continue
+		}
+		fp.stats.Inc("restored")
+		fp.logger.LogSuccess("Restored %s", rec.Path)
+		rec.Action = report.ActionRestored
+		fp.records = append(fp.records, rec)
+	}
+
+	if fp.stats.Get("failed") > 0 {
+		return errors.NewFileError("some files could not be restored, the run is kept", run.ID, "undo")
+	}
+	if fp.config.DryRun {
+		return nil
+	}
+	return run.Remove()
+}
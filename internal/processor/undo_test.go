@@ -0,0 +1,87 @@
+package processor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jeeftor/license-manager/internal/logger"
+	"github.com/jeeftor/license-manager/internal/report"
+)
+
+func TestUndo(t *testing.T) {
+	h := NewTestHelper(t, "Copyright (c) 2025 Acme Corp")
+	first := h.CreateFile("first.go", "package first\n")
+	second := h.CreateFile("second.go", "package second\n")
+
+	cfg := &Config{
+		LicenseText: h.LicenseText(),
+		Input:       filepath.Join(h.TmpDir(), "*.go"),
+		PresetStyle: "hash",
+		StateDir:    filepath.Join(h.TmpDir(), ".license-manager"),
+		LogLevel:    logger.ErrorLevel,
+	}
+	if err := NewFileProcessor(cfg).Add(); err != nil {
+		t.Fatalf("Add() failed: %v", err)
+	}
+	licensed := h.ReadFile(first)
+
+	cfg.Input = second
+	if err := NewFileProcessor(cfg).Remove(); err != nil {
+		t.Fatalf("Remove() failed: %v", err)
+	}
+
+	// A dry run only reports the files and keeps the run
+	dryRun := *cfg
+	dryRun.DryRun = true
+	processor := NewFileProcessor(&dryRun)
+	if err := processor.Undo("", false); err != nil {
+		t.Fatalf("Undo() in dry-run mode failed: %v", err)
+	}
+	if strings.Contains(h.ReadFile(second), "Acme Corp") {
+		t.Error("Undo() in dry-run mode restored a file")
+	}
+	if recs := processor.Records(); len(recs) != 1 || recs[0].Action != report.ActionDryRun {
+		t.Errorf("Undo() dry-run records = %+v", recs)
+	}
+	patch := *cfg
+	patch.PatchFile = filepath.Join(h.TmpDir(), "undo.patch")
+	if err := NewFileProcessor(&patch).Undo("", false); err == nil {
+		t.Error("Undo() should reject a patch file")
+	}
+
+	// The last run is undone first
+	processor = NewFileProcessor(cfg)
+	if err := processor.Undo("", false); err != nil {
+		t.Fatalf("Undo() failed: %v", err)
+	}
+	if !strings.Contains(h.ReadFile(second), "Acme Corp") {
+		t.Error("Undo() did not restore the license removed by the last run")
+	}
+	if recs := processor.Records(); len(recs) != 1 || recs[0].Action != report.ActionRestored {
+		t.Errorf("Undo() records = %+v", recs)
+	}
+
+	// Files changed since the run are protected
+	if err := os.WriteFile(first, []byte(licensed+"// edited\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := NewFileProcessor(cfg).Undo("", false); err == nil {
+		t.Fatal("Undo() should refuse to overwrite changed files")
+	}
+	if err := NewFileProcessor(cfg).Undo("", true); err != nil {
+		t.Fatalf("Undo() with force failed: %v", err)
+	}
+	if got := h.ReadFile(first); got != "package first\n" {
+		t.Errorf("first.go = %q after undo", got)
+	}
+	if got := h.ReadFile(second); got != "package second\n" {
+		t.Errorf("second.go = %q after undo", got)
+	}
+
+	runs, err := NewFileProcessor(cfg).Runs()
+	if err != nil || len(runs) != 0 {
+		t.Errorf("Runs() = %v, %v after undoing everything", runs, err)
+	}
+}
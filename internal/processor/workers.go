@@ -0,0 +1,163 @@
+package processor
+
+import (
+	"bytes"
+	"io"
+	"runtime"
+	"sync"
+
+	"github.com/jeeftor/license-manager/internal/license"
+	"github.com/jeeftor/license-manager/internal/report"
+)
+
+// fileResult is the outcome of processing a single file
+type fileResult struct {
+	record report.Record
+	status license.Status
+	err    error // stops the operation
+}
+
+// skipExcluded wraps process to report generated, vendored and binary files
+// marked in .gitattributes, and files whose content should not be licensed,
+// instead of processing them
+func skipExcluded(
+	process func(fp *FileProcessor, file string) fileResult,
+) func(fp *FileProcessor, file string) fileResult {
+	return func(fp *FileProcessor, file string) fileResult {
+		kind := fp.fileHandler.Exclusion(file)
+		if kind != "" {
+			fp.logger.LogInfo("Skipping %s file %s (.gitattributes)", kind, relativePath(file))
+		} else if kind = fp.fileHandler.ContentSkip(file); kind != "" {
+			fp.logger.LogInfo("Skipping %s file %s", kind, relativePath(file))
+		} else {
+			return process(fp, file)
+		}
+
+		fp.stats.Inc(kind)
+		return fileResult{record: report.Record{
+			Path:       relativePath(file),
+			Action:     report.ActionSkipped,
+			SkipReason: kind,
+		}}
+	}
+}
+
+// jobs returns the number of files processed concurrently
+func (fp *FileProcessor) jobs(files int) int {
+	if fp.config.Prompt {
+		return 1 // prompts read stdin, one file at a time
+	}
+	jobs := fp.config.Jobs
+	if jobs <= 0 {
+		jobs = runtime.NumCPU()
+	}
+	return max(1, min(jobs, files))
+}
+
+// withOutput returns a copy of the processor that logs to w. The copy shares
+// config and stats, so it can process files next to other workers.
+func (fp *FileProcessor) withOutput(w io.Writer) *FileProcessor {
+	worker := *fp
+	worker.records = nil
+	worker.logger = fp.logger.WithOutput(w)
+	fh := *fp.fileHandler
+	fh.logger = worker.logger
+	worker.fileHandler = &fh
+	return &worker
+}
+
+// processFiles runs process for every file and records the results in input order.
+// With more than one job, files are processed by a worker pool and the log output
+// of each file is buffered and flushed in input order, so output is the same as
+// a serial run. The first result with an error stops the operation.
+func (fp *FileProcessor) processFiles(
+	files []string,
+	process func(fp *FileProcessor, file string) fileResult,
+) ([]fileResult, error) {
+	process = copyUnchanged(skipExcluded(process))
+	jobs := fp.jobs(len(files))
+	results := make([]fileResult, 0, len(files))
+
+	if jobs == 1 {
+		for _, file := range files {
+			res := process(fp, file)
+			fp.records = append(fp.records, res.record)
+			results = append(results, res)
+			if res.err != nil {
+				return results, res.err
+			}
+		}
+		return results, nil
+	}
+
+	type job struct {
+		index int
+		file  string
+	}
+	type done struct {
+		index  int
+		output *bytes.Buffer
+		result fileResult
+	}
+
+	queue := make(chan job)
+	finished := make(chan done)
+	stop := make(chan struct{})
+
+	var wg sync.WaitGroup
+	for i := 0; i < jobs; i++ {
+		// Copied here, before the collector starts appending to fp.records
+		worker := fp.withOutput(nil)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range queue {
+				output := new(bytes.Buffer)
+				worker.logger.SetOutput(output)
+				res := process(worker, j.file)
+				select {
+				case finished <- done{j.index, output, res}:
+				case <-stop:
+					return
+				}
+			}
+		}()
+	}
+
+	go func() {
+		defer close(queue)
+		for i, file := range files {
+			select {
+			case queue <- job{i, file}:
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	// Collect results and flush them as soon as all earlier files are done
+	pending := make(map[int]done)
+	var err error
+	for len(results) < len(files) && err == nil {
+		d := <-finished
+		pending[d.index] = d
+		for {
+			next, ok := pending[len(results)]
+			if !ok {
+				break
+			}
+			delete(pending, next.index)
+			fp.logger.Write(next.output.Bytes())
+			fp.records = append(fp.records, next.result.record)
+			results = append(results, next.result)
+			if next.result.err != nil {
+				err = next.result.err
+				break
+			}
+		}
+	}
+
+	close(stop)
+	wg.Wait()
+	return results, err
+}
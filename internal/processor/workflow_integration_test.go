@@ -38,8 +38,8 @@ func TestFullWorkflow(t *testing.T) {
 		}
 
 		// Verify stats
-		if processor.stats["added"] != 2 {
-			t.Errorf("Expected 2 files added, got %d", processor.stats["added"])
+		if processor.stats.Get("added") != 2 {
+			t.Errorf("Expected 2 files added, got %d", processor.stats.Get("added"))
 		}
 	})
 
@@ -52,11 +52,11 @@ func TestFullWorkflow(t *testing.T) {
 		}
 
 		// Verify stats - the key is "passed" per file_processor.go:417
-		if processor.stats["passed"] != 2 {
+		if processor.stats.Get("passed") != 2 {
 			t.Errorf(
 				"Expected 2 files passed, got %d (stats: %+v)",
-				processor.stats["passed"],
-				processor.stats,
+				processor.stats.Get("passed"),
+				processor.stats.Snapshot(),
 			)
 		}
 	})
@@ -86,8 +86,8 @@ func TestFullWorkflow(t *testing.T) {
 		}
 
 		// Verify stats
-		if processor.stats["updated"] != 2 {
-			t.Errorf("Expected 2 files updated, got %d", processor.stats["updated"])
+		if processor.stats.Get("updated") != 2 {
+			t.Errorf("Expected 2 files updated, got %d", processor.stats.Get("updated"))
 		}
 	})
 
@@ -103,11 +103,11 @@ func TestFullWorkflow(t *testing.T) {
 		}
 
 		// Should have failed files
-		if processor.stats["failed"] < 2 {
+		if processor.stats.Get("failed") < 2 {
 			t.Errorf(
 				"Expected at least 2 failed files, got %d (stats: %+v)",
-				processor.stats["failed"],
-				processor.stats,
+				processor.stats.Get("failed"),
+				processor.stats.Snapshot(),
 			)
 		}
 	})
@@ -142,8 +142,8 @@ func TestFullWorkflow(t *testing.T) {
 		}
 
 		// Verify stats
-		if processor.stats["removed"] != 2 {
-			t.Errorf("Expected 2 files removed, got %d", processor.stats["removed"])
+		if processor.stats.Get("removed") != 2 {
+			t.Errorf("Expected 2 files removed, got %d", processor.stats.Get("removed"))
 		}
 	})
 
@@ -159,11 +159,11 @@ func TestFullWorkflow(t *testing.T) {
 		}
 
 		// Should report missing licenses
-		if processor.stats["missing"] != 2 {
+		if processor.stats.Get("missing") != 2 {
 			t.Errorf(
 				"Expected 2 files missing licenses, got %d (stats: %+v)",
-				processor.stats["missing"],
-				processor.stats,
+				processor.stats.Get("missing"),
+				processor.stats.Snapshot(),
 			)
 		}
 	})
@@ -250,8 +250,8 @@ func TestWorkflowWithSkipPatterns(t *testing.T) {
 		}
 
 		// Verify stats
-		if processor.stats["added"] != 1 {
-			t.Errorf("Expected 1 file added, got %d", processor.stats["added"])
+		if processor.stats.Get("added") != 1 {
+			t.Errorf("Expected 1 file added, got %d", processor.stats.Get("added"))
 		}
 	})
 }
@@ -283,11 +283,11 @@ func TestWorkflowIdempotency(t *testing.T) {
 	}
 
 	// Stats should show existing
-	if processor.stats["existing"] != 1 {
-		t.Errorf("Expected 1 existing license, got %d", processor.stats["existing"])
+	if processor.stats.Get("existing") != 1 {
+		t.Errorf("Expected 1 existing license, got %d", processor.stats.Get("existing"))
 	}
-	if processor.stats["added"] != 0 {
-		t.Errorf("Expected 0 added, got %d", processor.stats["added"])
+	if processor.stats.Get("added") != 0 {
+		t.Errorf("Expected 0 added, got %d", processor.stats.Get("added"))
 	}
 }
 
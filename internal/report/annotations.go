@@ -0,0 +1,48 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Annotation modes
+const (
+	AnnotateGitHub = "github" // GitHub Actions workflow commands
+)
+
+// githubData escapes the message of a workflow command
+var githubData = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A")
+
+// githubProperty escapes a property value of a workflow command
+var githubProperty = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C")
+
+// WriteGitHubAnnotations writes an ::error workflow command for every failed
+// check or unprocessable file, so GitHub Actions shows them inline on the diff
+func WriteGitHubAnnotations(w io.Writer, r Report) error {
+	for _, rec := range r.Files {
+		message := rec.Error
+		title := "License check error"
+		if message == "" {
+			if rec.Reason == "" {
+				continue
+			}
+			message = problemDetails(rec)
+			title = rec.Reason
+		}
+
+		props := []string{"file=" + githubProperty.Replace(rec.Path)}
+		if rec.StartLine > 0 {
+			props = append(props, fmt.Sprintf("line=%d", rec.StartLine))
+			if rec.EndLine > rec.StartLine {
+				props = append(props, fmt.Sprintf("endLine=%d", rec.EndLine))
+			}
+		}
+		props = append(props, "title="+githubProperty.Replace(title))
+
+		if _, err := fmt.Fprintf(w, "::error %s::%s\n", strings.Join(props, ","), githubData.Replace(message)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+package report
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriteGitHubAnnotations(t *testing.T) {
+	r := Report{
+		Command: "check",
+		Files: []Record{
+			{Path: "ok.go", Status: "FullMatch", Action: ActionChecked, StartLine: 1, EndLine: 5},
+			{Path: "missing.py", Status: "NoLicense", Action: ActionChecked, Reason: "Missing license", StartLine: 2, EndLine: 2},
+			{Path: "dir,1/a:b.go", Status: "ContentMismatch", Action: ActionChecked, Reason: "License content mismatch", Diff: "-a\n+b\n", StartLine: 1, EndLine: 4},
+			{Path: "broken.go", Action: ActionFailed, Error: "100% denied"},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteGitHubAnnotations(&buf, r); err != nil {
+		t.Fatalf("WriteGitHubAnnotations() failed: %v", err)
+	}
+
+	want := "::error file=missing.py,line=2,title=Missing license::Missing license\n" +
+		"::error file=dir%2C1/a%3Ab.go,line=1,endLine=4,title=License content mismatch::License content mismatch%0A%0A-a%0A+b%0A\n" +
+		"::error file=broken.go,title=License check error::100%25 denied\n"
+	if got := buf.String(); got != want {
+		t.Errorf("WriteGitHubAnnotations() =\n%s\nwant\n%s", got, want)
+	}
+}
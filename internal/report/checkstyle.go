@@ -0,0 +1,53 @@
+package report
+
+import (
+	"encoding/xml"
+	"io"
+)
+
+// checkstyleVersion is the Checkstyle report format version understood by CI tools
+const checkstyleVersion = "4.3"
+
+type checkstyleReport struct {
+	XMLName xml.Name         `xml:"checkstyle"`
+	Version string           `xml:"version,attr"`
+	Files   []checkstyleFile `xml:"file"`
+}
+
+type checkstyleFile struct {
+	Name   string            `xml:"name,attr"`
+	Errors []checkstyleError `xml:"error"`
+}
+
+type checkstyleError struct {
+	Line     int    `xml:"line,attr,omitempty"`
+	Severity string `xml:"severity,attr"`
+	Message  string `xml:"message,attr"`
+	Source   string `xml:"source,attr"`
+}
+
+// WriteCheckstyle writes one Checkstyle file entry per file, with an error
+// for every failed check or file that could not be processed
+func WriteCheckstyle(w io.Writer, r Report) error {
+	report := checkstyleReport{Version: checkstyleVersion}
+	for _, rec := range r.Files {
+		file := checkstyleFile{Name: rec.Path}
+		switch {
+		case rec.Error != "":
+			file.Errors = append(file.Errors, checkstyleError{
+				Severity: "error",
+				Message:  rec.Error,
+				Source:   toolName + ".ProcessingError",
+			})
+		case rec.Reason != "":
+			file.Errors = append(file.Errors, checkstyleError{
+				Line:     rec.StartLine,
+				Severity: "error",
+				Message:  problemDetails(rec),
+				Source:   toolName + "." + rec.Status,
+			})
+		}
+		report.Files = append(report.Files, file)
+	}
+	return writeXML(w, report)
+}
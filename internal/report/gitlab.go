@@ -0,0 +1,71 @@
+package report
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"path/filepath"
+)
+
+// gitlabIssue is an entry of a GitLab Code Quality report
+type gitlabIssue struct {
+	Description string         `json:"description"`
+	CheckName   string         `json:"check_name"`
+	Fingerprint string         `json:"fingerprint"`
+	Severity    string         `json:"severity"`
+	Location    gitlabLocation `json:"location"`
+}
+
+type gitlabLocation struct {
+	Path  string      `json:"path"`
+	Lines gitlabLines `json:"lines"`
+}
+
+type gitlabLines struct {
+	Begin int `json:"begin"`
+}
+
+// WriteGitLab writes a GitLab Code Quality report with one issue per failed
+// check or unprocessable file, shown inline on merge request diffs
+func WriteGitLab(w io.Writer, r Report) error {
+	issues := []gitlabIssue{}
+	for _, rec := range r.Files {
+		checkName := toolName + "/" + rec.Status
+		description := rec.Reason
+		severity := "major"
+		if rec.Error != "" {
+			checkName = toolName + "/ProcessingError"
+			description = rec.Error
+			severity = "critical"
+		} else if rec.Reason == "" {
+			continue
+		}
+
+		line := rec.StartLine
+		if line < 1 {
+			line = 1
+		}
+		issues = append(issues, gitlabIssue{
+			Description: description,
+			CheckName:   checkName,
+			Fingerprint: fingerprint(checkName, rec.Path),
+			Severity:    severity,
+			Location:    gitlabLocation{Path: filepath.ToSlash(rec.Path), Lines: gitlabLines{Begin: line}},
+		})
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(issues)
+}
+
+// fingerprint identifies an issue across pipelines so GitLab can track it
+func fingerprint(parts ...string) string {
+	h := sha256.New()
+	for _, part := range parts {
+		io.WriteString(h, part)
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,52 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestWriteGitLab(t *testing.T) {
+	r := Report{
+		Command: "check",
+		Files: []Record{
+			{Path: "ok.go", Status: "FullMatch", Action: ActionChecked, StartLine: 1, EndLine: 5},
+			{Path: "missing.py", Status: "NoLicense", Action: ActionChecked, Reason: "Missing license", StartLine: 2, EndLine: 2},
+			{Path: "broken.go", Action: ActionFailed, Error: "permission denied"},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, FormatGitLab, r); err != nil {
+		t.Fatalf("Write() failed: %v", err)
+	}
+
+	var issues []gitlabIssue
+	if err := json.Unmarshal(buf.Bytes(), &issues); err != nil {
+		t.Fatalf("Output is not valid JSON: %v\n%s", err, buf.String())
+	}
+	if len(issues) != 2 {
+		t.Fatalf("Expected 2 issues, got %d", len(issues))
+	}
+
+	missing := issues[0]
+	if missing.CheckName != "license-manager/NoLicense" || missing.Severity != "major" ||
+		missing.Location.Path != "missing.py" || missing.Location.Lines.Begin != 2 {
+		t.Errorf("Unexpected issue: %+v", missing)
+	}
+	broken := issues[1]
+	if broken.Description != "permission denied" || broken.Severity != "critical" || broken.Location.Lines.Begin != 1 {
+		t.Errorf("Unexpected issue: %+v", broken)
+	}
+	if missing.Fingerprint == "" || missing.Fingerprint == broken.Fingerprint {
+		t.Errorf("Fingerprints should be unique: %q, %q", missing.Fingerprint, broken.Fingerprint)
+	}
+
+	buf.Reset()
+	if err := WriteGitLab(&buf, Report{Command: "check"}); err != nil {
+		t.Fatalf("WriteGitLab() failed: %v", err)
+	}
+	if got := buf.String(); got != "[]\n" {
+		t.Errorf("Empty report = %q, want []", got)
+	}
+}
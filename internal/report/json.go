@@ -0,0 +1,17 @@
+package report
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// WriteJSON writes the report as an indented JSON document
+func WriteJSON(w io.Writer, r Report) error {
+	if r.Files == nil {
+		r.Files = []Record{}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
@@ -0,0 +1,57 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestWriteJSON(t *testing.T) {
+	r := Report{
+		Command: "check",
+		Files: []Record{
+			{Path: "main.go", Language: "go", Handler: "GoHandler", Style: "Hash", Status: "FullMatch", Action: ActionChecked},
+			{Path: "broken.go", Language: "go", Action: ActionFailed, Error: "read failed"},
+		},
+		Totals: map[string]int{"passed": 1, "failed": 1},
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, FormatJSON, r); err != nil {
+		t.Fatalf("Write() failed: %v", err)
+	}
+
+	var decoded Report
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("Output is not valid JSON: %v\n%s", err, buf.String())
+	}
+	if decoded.Command != "check" || len(decoded.Files) != 2 || decoded.Totals["failed"] != 1 {
+		t.Errorf("Unexpected round trip: %+v", decoded)
+	}
+	if decoded.Files[1].Error != "read failed" {
+		t.Errorf("Error not preserved: %+v", decoded.Files[1])
+	}
+
+	// Empty fields are omitted, files is always a list
+	buf.Reset()
+	if err := WriteJSON(&buf, Report{Command: "add"}); err != nil {
+		t.Fatalf("WriteJSON() failed: %v", err)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte(`"files": []`)) {
+		t.Errorf("Expected empty files list, got:\n%s", buf.String())
+	}
+}
+
+func TestValidateFormat(t *testing.T) {
+	for _, format := range Formats() {
+		if err := ValidateFormat(format); err != nil {
+			t.Errorf("ValidateFormat(%q) = %v", format, err)
+		}
+	}
+	if err := ValidateFormat("yaml"); err == nil {
+		t.Error("ValidateFormat(\"yaml\") should fail")
+	}
+	if err := Write(&bytes.Buffer{}, "yaml", Report{}); err == nil {
+		t.Error("Write() with unknown format should fail")
+	}
+}
@@ -0,0 +1,100 @@
+package report
+
+import (
+	"encoding/xml"
+	"io"
+)
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	File      string        `xml:"file,attr,omitempty"`
+	Failure   *junitProblem `xml:"failure,omitempty"`
+	Error     *junitProblem `xml:"error,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+}
+
+type junitProblem struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Body    string `xml:",cdata"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+// WriteJUnit writes one JUnit test case per file. Failed checks become
+// failures carrying the reason and diff, unprocessable files become errors and
+// files excluded from licensing are skipped.
+func WriteJUnit(w io.Writer, r Report) error {
+	suite := junitTestSuite{Name: toolName + " " + r.Command}
+	for _, rec := range r.Files {
+		tc := junitTestCase{
+			Name:      rec.Path,
+			ClassName: toolName + "." + r.Command,
+			File:      rec.Path,
+		}
+		switch {
+		case rec.Error != "":
+			tc.Error = &junitProblem{Message: rec.Error, Type: "ProcessingError", Body: rec.Error}
+			suite.Errors++
+		case rec.Reason != "":
+			tc.Failure = &junitProblem{Message: rec.Reason, Type: rec.Status, Body: problemDetails(rec)}
+			suite.Failures++
+		case rec.SkipReason != "":
+			tc.Skipped = &junitSkipped{Message: rec.SkipReason}
+			suite.Skipped++
+		}
+		suite.TestCases = append(suite.TestCases, tc)
+	}
+	suite.Tests = len(suite.TestCases)
+
+	return writeXML(w, junitTestSuites{
+		Name:     toolName,
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Errors:   suite.Errors,
+		Suites:   []junitTestSuite{suite},
+	})
+}
+
+// problemDetails returns the reason of a failed check followed by its diff
+func problemDetails(rec Record) string {
+	if rec.Diff == "" {
+		return rec.Reason
+	}
+	return rec.Reason + "\n\n" + rec.Diff
+}
+
+// writeXML writes an indented XML document with header
+func writeXML(w io.Writer, v interface{}) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
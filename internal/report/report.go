@@ -0,0 +1,106 @@
+// Package report renders the per-file results of a license-manager run in machine-readable formats
+package report
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/jeeftor/license-manager/internal/errors"
+)
+
+// Actions taken on a file
+const (
+	ActionAdded     = "added"
+	ActionUpdated   = "updated"
+	ActionRemoved   = "removed"
+	ActionRestored  = "restored"  // undo wrote the original content back
+	ActionUnchanged = "unchanged" // nothing to do
+	ActionExisting  = "existing"  // add found a license already
+	ActionSkipped   = "skipped"
+	ActionDryRun    = "dry-run" // change computed but not written
+	ActionChecked   = "checked"
+	ActionFailed    = "failed"
+)
+
+// Output formats
+const (
+	FormatText       = "text" // human readable log output only
+	FormatJSON       = "json"
+	FormatSARIF      = "sarif"      // SARIF 2.1.0, check results only
+	FormatJUnit      = "junit"      // JUnit XML, check results only
+	FormatCheckstyle = "checkstyle" // Checkstyle XML, check results only
+	FormatGitLab     = "gitlab"     // GitLab Code Quality JSON, check results only
+)
+
+// Record describes the outcome for a single file
+type Record struct {
+	Path     string `json:"path"`               // Path relative to the working directory
+	Language string `json:"language,omitempty"` // Language detected from the extension
+	Handler  string `json:"handler,omitempty"`  // Language handler type
+	Style    string `json:"style,omitempty"`    // Header style detected in the file
+	Status   string `json:"status,omitempty"`   // license.Status name, when the license was compared
+	Action   string `json:"action"`             // One of the Action constants
+	Error    string `json:"error,omitempty"`
+	Reason   string `json:"reason,omitempty"` // Why a check failed
+	Diff     string `json:"diff,omitempty"`   // Unified diff from the expected to the actual license text, or of the change with --diff
+
+	SkipReason string `json:"skip_reason,omitempty"` // Why the file was not processed
+
+	// 1-based line range of the license header, or the line where it belongs
+	StartLine int `json:"start_line,omitempty"`
+	EndLine   int `json:"end_line,omitempty"`
+}
+
+// Report holds the records and totals of one command run
+type Report struct {
+	Command string         `json:"command"`
+	Version string         `json:"version,omitempty"` // license-manager version
+	Files   []Record       `json:"files"`
+	Totals  map[string]int `json:"totals"`
+}
+
+// Formats returns the supported output formats
+func Formats() []string {
+	return []string{FormatText, FormatJSON, FormatSARIF, FormatJUnit, FormatCheckstyle, FormatGitLab}
+}
+
+// ForChecks reports whether a format only describes check findings
+func ForChecks(format string) bool {
+	switch format {
+	case FormatSARIF, FormatJUnit, FormatCheckstyle, FormatGitLab:
+		return true
+	}
+	return false
+}
+
+// ValidateFormat returns an error for unsupported output formats
+func ValidateFormat(format string) error {
+	for _, f := range Formats() {
+		if f == format {
+			return nil
+		}
+	}
+	return errors.NewValidationError(
+		fmt.Sprintf("unsupported output format %q (supported: %v)", format, Formats()), "Format")
+}
+
+// Write renders the report in the given format. Text output is produced by
+// the logger while processing, so nothing is written for FormatText.
+func Write(w io.Writer, format string, r Report) error {
+	switch format {
+	case FormatText:
+		return nil
+	case FormatJSON:
+		return WriteJSON(w, r)
+	case FormatSARIF:
+		return WriteSARIF(w, r)
+	case FormatJUnit:
+		return WriteJUnit(w, r)
+	case FormatCheckstyle:
+		return WriteCheckstyle(w, r)
+	case FormatGitLab:
+		return WriteGitLab(w, r)
+	default:
+		return ValidateFormat(format)
+	}
+}
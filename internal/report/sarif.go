@@ -0,0 +1,199 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/jeeftor/license-manager/internal/license"
+)
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	toolName     = "license-manager"
+	toolURI      = "https://github.com/jeeftor/license-manager"
+)
+
+// sarifRules are reported in this order, one rule per failing license.Status
+var sarifRules = []struct {
+	status      license.Status
+	description string
+	help        string
+}{
+	{
+		license.NoLicense,
+		"The file has no license header",
+		"Run `license-manager add` to add the license header.",
+	},
+	{
+		license.ContentMismatch,
+		"The license header text differs from the expected license",
+		"Run `license-manager update` to replace the license header.",
+	},
+	{
+		license.StyleMismatch,
+		"The license header uses a different header/footer style",
+		"Run `license-manager update` with the expected --style.",
+	},
+	{
+		license.ContentAndStyleMismatch,
+		"The license header text and header/footer style differ from the expected license",
+		"Run `license-manager update` to replace the license header.",
+	},
+	{
+		license.OutdatedYear,
+		"The license header has outdated copyright years",
+		"Run `license-manager update` with the same --year-policy to fix the years.",
+	},
+}
+
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool        sarifTool         `json:"tool"`
+	Invocations []sarifInvocation `json:"invocations"`
+	Results     []sarifResult     `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version,omitempty"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string            `json:"id"`
+	ShortDescription     sarifMessage      `json:"shortDescription"`
+	Help                 sarifMessage      `json:"help"`
+	DefaultConfiguration sarifRuleDefaults `json:"defaultConfiguration"`
+}
+
+type sarifRuleDefaults struct {
+	Level string `json:"level"`
+}
+
+type sarifInvocation struct {
+	ExecutionSuccessful        bool                `json:"executionSuccessful"`
+	ToolExecutionNotifications []sarifNotification `json:"toolExecutionNotifications,omitempty"`
+}
+
+type sarifNotification struct {
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations,omitempty"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	RuleIndex int             `json:"ruleIndex"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+	EndLine   int `json:"endLine,omitempty"`
+}
+
+// WriteSARIF writes the check results as a SARIF 2.1.0 log with one result per
+// failing file. Files that could not be processed become tool notifications.
+func WriteSARIF(w io.Writer, r Report) error {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           toolName,
+			Version:        r.Version,
+			InformationURI: toolURI,
+		}},
+		Results: []sarifResult{},
+	}
+
+	ruleIndex := make(map[string]int, len(sarifRules))
+	for i, rule := range sarifRules {
+		ruleIndex[rule.status.Name()] = i
+		run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{
+			ID:                   rule.status.Name(),
+			ShortDescription:     sarifMessage{Text: rule.description},
+			Help:                 sarifMessage{Text: rule.help},
+			DefaultConfiguration: sarifRuleDefaults{Level: "error"},
+		})
+	}
+
+	invocation := sarifInvocation{ExecutionSuccessful: true}
+	for _, rec := range r.Files {
+		if rec.Error != "" {
+			invocation.ExecutionSuccessful = false
+			invocation.ToolExecutionNotifications = append(invocation.ToolExecutionNotifications, sarifNotification{
+				Level:     "error",
+				Message:   sarifMessage{Text: rec.Error},
+				Locations: []sarifLocation{sarifLocationFor(rec)},
+			})
+			continue
+		}
+
+		index, ok := ruleIndex[rec.Status]
+		if !ok {
+			continue // passed, or not compared
+		}
+		message := rec.Reason
+		if message == "" {
+			message = sarifRules[index].description
+		}
+		run.Results = append(run.Results, sarifResult{
+			RuleID:    rec.Status,
+			RuleIndex: index,
+			Level:     "error",
+			Message:   sarifMessage{Text: fmt.Sprintf("%s: %s", rec.Path, message)},
+			Locations: []sarifLocation{sarifLocationFor(rec)},
+		})
+	}
+	run.Invocations = []sarifInvocation{invocation}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(sarifLog{
+		Version: sarifVersion,
+		Schema:  sarifSchema,
+		Runs:    []sarifRun{run},
+	})
+}
+
+// sarifLocationFor points at the license header lines of a record
+func sarifLocationFor(rec Record) sarifLocation {
+	loc := sarifLocation{PhysicalLocation: sarifPhysicalLocation{
+		ArtifactLocation: sarifArtifactLocation{URI: filepath.ToSlash(rec.Path), URIBaseID: "%SRCROOT%"},
+	}}
+	if rec.StartLine > 0 {
+		loc.PhysicalLocation.Region = &sarifRegion{StartLine: rec.StartLine, EndLine: rec.EndLine}
+	}
+	return loc
+}
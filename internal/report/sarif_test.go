@@ -0,0 +1,72 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestWriteSARIF(t *testing.T) {
+	r := Report{
+		Command: "check",
+		Version: "1.2.3",
+		Files: []Record{
+			{Path: "ok.go", Status: "FullMatch", Action: ActionChecked, StartLine: 1, EndLine: 5},
+			{Path: "missing.py", Status: "NoLicense", Action: ActionChecked, StartLine: 2, EndLine: 2},
+			{Path: "old.go", Status: "StyleMismatch", Action: ActionChecked, StartLine: 1, EndLine: 6},
+			{Path: "broken.go", Action: ActionFailed, Error: "permission denied"},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, FormatSARIF, r); err != nil {
+		t.Fatalf("Write() failed: %v", err)
+	}
+
+	var log sarifLog
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatalf("Output is not valid JSON: %v\n%s", err, buf.String())
+	}
+	if log.Version != "2.1.0" || len(log.Runs) != 1 {
+		t.Fatalf("Unexpected SARIF envelope: %+v", log)
+	}
+	run := log.Runs[0]
+
+	if run.Tool.Driver.Version != "1.2.3" {
+		t.Errorf("Driver version = %q, want 1.2.3", run.Tool.Driver.Version)
+	}
+	var ruleIDs []string
+	for _, rule := range run.Tool.Driver.Rules {
+		ruleIDs = append(ruleIDs, rule.ID)
+	}
+	wantRules := []string{"NoLicense", "ContentMismatch", "StyleMismatch", "ContentAndStyleMismatch", "OutdatedYear"}
+	if len(ruleIDs) != len(wantRules) {
+		t.Fatalf("Rules = %v, want %v", ruleIDs, wantRules)
+	}
+	for i := range wantRules {
+		if ruleIDs[i] != wantRules[i] {
+			t.Errorf("Rule %d = %q, want %q", i, ruleIDs[i], wantRules[i])
+		}
+	}
+
+	if len(run.Results) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(run.Results))
+	}
+	missing := run.Results[0]
+	if missing.RuleID != "NoLicense" || missing.RuleIndex != 0 {
+		t.Errorf("Unexpected result: %+v", missing)
+	}
+	loc := missing.Locations[0].PhysicalLocation
+	if loc.ArtifactLocation.URI != "missing.py" || loc.Region == nil || loc.Region.StartLine != 2 {
+		t.Errorf("Unexpected location: %+v", loc)
+	}
+	if style := run.Results[1]; style.RuleID != "StyleMismatch" || style.RuleIndex != 2 ||
+		style.Locations[0].PhysicalLocation.Region.EndLine != 6 {
+		t.Errorf("Unexpected result: %+v", style)
+	}
+
+	inv := run.Invocations[0]
+	if inv.ExecutionSuccessful || len(inv.ToolExecutionNotifications) != 1 {
+		t.Errorf("Processing errors should be tool notifications: %+v", inv)
+	}
+}
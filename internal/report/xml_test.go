@@ -0,0 +1,95 @@
+package report
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+// checkReport is a check run with a passing, a failing and an unprocessable file
+var checkReport = Report{
+	Command: "check",
+	Files: []Record{
+		{Path: "ok.go", Status: "FullMatch", Action: ActionChecked},
+		{
+			Path: "old.go", Status: "ContentMismatch", Action: ActionChecked, StartLine: 2,
+			Reason: "License content mismatch", Diff: "--- expected\n+++ actual\n@@ -1 +1 @@\n-MIT\n+BSD\n",
+		},
+		{Path: "broken.go", Action: ActionFailed, Error: "permission denied"},
+	},
+}
+
+func TestWriteJUnit(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, FormatJUnit, checkReport); err != nil {
+		t.Fatalf("Write() failed: %v", err)
+	}
+
+	var suites junitTestSuites
+	if err := xml.Unmarshal(buf.Bytes(), &suites); err != nil {
+		t.Fatalf("Output is not valid XML: %v\n%s", err, buf.String())
+	}
+	if suites.Tests != 3 || suites.Failures != 1 || suites.Errors != 1 {
+		t.Errorf("Unexpected totals: tests=%d failures=%d errors=%d", suites.Tests, suites.Failures, suites.Errors)
+	}
+
+	cases := suites.Suites[0].TestCases
+	if cases[0].Failure != nil || cases[0].Error != nil {
+		t.Errorf("Passing file should have no failure: %+v", cases[0])
+	}
+	failure := cases[1].Failure
+	if failure == nil || failure.Type != "ContentMismatch" || failure.Message != "License content mismatch" {
+		t.Fatalf("Unexpected failure: %+v", failure)
+	}
+	if !strings.Contains(failure.Body, "-MIT\n+BSD\n") {
+		t.Errorf("Failure should contain the diff, got %q", failure.Body)
+	}
+	if cases[2].Error == nil || cases[2].Error.Message != "permission denied" {
+		t.Errorf("Unexpected error: %+v", cases[2].Error)
+	}
+
+	buf.Reset()
+	skipped := Report{Command: "check", Files: []Record{{Path: "vendor/lib.go", Action: ActionSkipped, SkipReason: "vendored"}}}
+	if err := Write(&buf, FormatJUnit, skipped); err != nil {
+		t.Fatalf("Write() failed: %v", err)
+	}
+	var skippedSuites junitTestSuites
+	if err := xml.Unmarshal(buf.Bytes(), &skippedSuites); err != nil {
+		t.Fatalf("Output is not valid XML: %v\n%s", err, buf.String())
+	}
+	suite := skippedSuites.Suites[0]
+	if suite.Skipped != 1 || suite.TestCases[0].Skipped == nil || suite.TestCases[0].Skipped.Message != "vendored" {
+		t.Errorf("Excluded file should be skipped: %+v", suite)
+	}
+}
+
+func TestWriteCheckstyle(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, FormatCheckstyle, checkReport); err != nil {
+		t.Fatalf("Write() failed: %v", err)
+	}
+
+	var report checkstyleReport
+	if err := xml.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatalf("Output is not valid XML: %v\n%s", err, buf.String())
+	}
+	if len(report.Files) != 3 {
+		t.Fatalf("Expected 3 files, got %d", len(report.Files))
+	}
+	if len(report.Files[0].Errors) != 0 {
+		t.Errorf("Passing file should have no errors: %+v", report.Files[0])
+	}
+
+	mismatch := report.Files[1].Errors
+	if len(mismatch) != 1 || mismatch[0].Line != 2 || mismatch[0].Source != "license-manager.ContentMismatch" {
+		t.Fatalf("Unexpected errors: %+v", mismatch)
+	}
+	if !strings.HasPrefix(mismatch[0].Message, "License content mismatch\n") ||
+		!strings.Contains(mismatch[0].Message, "+BSD") {
+		t.Errorf("Message should contain reason and diff, got %q", mismatch[0].Message)
+	}
+	if errs := report.Files[2].Errors; len(errs) != 1 || errs[0].Message != "permission denied" {
+		t.Errorf("Unexpected errors: %+v", errs)
+	}
+}